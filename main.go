@@ -2,16 +2,24 @@ package main
 
 import (
 	"context"
+	"log"
 
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/plugin"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6/tf6server"
+	"github.com/hashicorp/terraform-plugin-mux/tf6muxserver"
 	"github.com/trickest/terraform-provider-ory-keto/provider"
 )
 
 func main() {
-	plugin.Serve(&plugin.ServeOpts{
-		ProviderFunc: func() *schema.Provider {
-			return provider.Provider(context.Background())
-		},
-	})
+	ctx := context.Background()
+	servers, err := provider.ProviderServers(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+	muxServer, err := tf6muxserver.NewMuxServer(ctx, servers...)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := tf6server.Serve("registry.terraform.io/76creates/oryketo", muxServer.ProviderServer); err != nil {
+		log.Fatal(err)
+	}
 }
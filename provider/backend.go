@@ -0,0 +1,246 @@
+package provider
+
+import (
+	"context"
+	"errors"
+
+	ketoClient "github.com/ory/keto-client-go"
+)
+
+const (
+	protocolRest = "rest"
+	protocolGrpc = "grpc"
+)
+
+// readConsistency selects the snapshot a check is evaluated at, the zero
+// value leaves the choice to the server.
+type readConsistency struct {
+	snaptoken string
+	latest    bool
+}
+
+// consistencyLatest evaluates checks at the latest snapshot.
+const consistencyLatest = "latest"
+
+// errConsistencyNotSupported is returned by backends that can't pass
+// snaptokens or consistency settings to Keto.
+var errConsistencyNotSupported = errors.New(`snaptoken and consistency are only supported with protocol = "grpc"`)
+
+// ketoBackend is implemented by every protocol the provider can use to talk
+// to Keto, resources and data sources only interact with Keto through it.
+type ketoBackend interface {
+	getRelationships(ctx context.Context, query ketoClient.RelationQuery, pageToken string, pageSize int64, snaptoken string) (*ketoClient.Relationships, error)
+	// createRelationship and patchRelationships return the snaptoken of the
+	// write, which is empty when the server doesn't provide one
+	createRelationship(ctx context.Context, rel ketoClient.Relationship) (string, error)
+	deleteRelationships(ctx context.Context, query ketoClient.RelationQuery) error
+	patchRelationships(ctx context.Context, patches []ketoClient.RelationshipPatch) (string, error)
+	checkPermission(ctx context.Context, rel ketoClient.Relationship, maxDepth int64, consistency readConsistency) (bool, error)
+	expandPermissions(ctx context.Context, subjectSet ketoClient.SubjectSet, maxDepth int64, snaptoken string) (*ketoClient.ExpandedPermissionTree, error)
+	checkOplSyntax(ctx context.Context, opl string) ([]ketoClient.ParseError, error)
+	listNamespaces(ctx context.Context) ([]string, error)
+}
+
+// restBackend talks to Keto through the REST API.
+type restBackend struct {
+	readApiClient  *ketoClient.APIClient
+	writeApiClient *ketoClient.APIClient
+	oplApiClient   *ketoClient.APIClient
+}
+
+func (b *restBackend) getRelationships(ctx context.Context, query ketoClient.RelationQuery, pageToken string, pageSize int64, snaptoken string) (*ketoClient.Relationships, error) {
+	if snaptoken != "" {
+		return nil, errConsistencyNotSupported
+	}
+
+	request := b.readApiClient.RelationshipApi.
+		GetRelationships(ctx).
+		PageSize(pageSize)
+	if query.Namespace != nil {
+		request = request.Namespace(*query.Namespace)
+	}
+	if query.Object != nil {
+		request = request.Object(*query.Object)
+	}
+	if query.Relation != nil {
+		request = request.Relation(*query.Relation)
+	}
+	if query.SubjectId != nil {
+		request = request.SubjectId(*query.SubjectId)
+	} else if query.SubjectSet != nil {
+		request = request.
+			SubjectSetNamespace(query.SubjectSet.Namespace).
+			SubjectSetObject(query.SubjectSet.Object).
+			SubjectSetRelation(query.SubjectSet.Relation)
+	}
+	if pageToken != "" {
+		request = request.PageToken(pageToken)
+	}
+
+	relationships, resp, err := request.Execute()
+	if err != nil {
+		return nil, newRestError("list relationships", resp, err)
+	}
+	return relationships, nil
+}
+
+func (b *restBackend) createRelationship(ctx context.Context, rel ketoClient.Relationship) (string, error) {
+	body := ketoClient.CreateRelationshipBody{
+		Namespace:  &rel.Namespace,
+		Object:     &rel.Object,
+		Relation:   &rel.Relation,
+		SubjectId:  rel.SubjectId,
+		SubjectSet: rel.SubjectSet,
+	}
+
+	// Todo: validate returned relationship
+	_, resp, err := b.writeApiClient.RelationshipApi.
+		CreateRelationship(ctx).
+		CreateRelationshipBody(body).
+		Execute()
+	if err != nil {
+		return "", newRestError("create relationship", resp, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 201 {
+		return "", newRestError("create relationship", resp, nil)
+	}
+	return "", nil
+}
+
+func (b *restBackend) deleteRelationships(ctx context.Context, query ketoClient.RelationQuery) error {
+	request := b.writeApiClient.RelationshipApi.DeleteRelationships(ctx)
+	if query.Namespace != nil {
+		request = request.Namespace(*query.Namespace)
+	}
+	if query.Object != nil {
+		request = request.Object(*query.Object)
+	}
+	if query.Relation != nil {
+		request = request.Relation(*query.Relation)
+	}
+	if query.SubjectId != nil {
+		request = request.SubjectId(*query.SubjectId)
+	} else if query.SubjectSet != nil {
+		request = request.
+			SubjectSetNamespace(query.SubjectSet.Namespace).
+			SubjectSetObject(query.SubjectSet.Object).
+			SubjectSetRelation(query.SubjectSet.Relation)
+	}
+
+	resp, err := request.Execute()
+	if err != nil {
+		return newRestError("delete relationships", resp, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 204 {
+		return newRestError("delete relationships", resp, nil)
+	}
+	return nil
+}
+
+func (b *restBackend) patchRelationships(ctx context.Context, patches []ketoClient.RelationshipPatch) (string, error) {
+	resp, err := b.writeApiClient.RelationshipApi.
+		PatchRelationships(ctx).
+		RelationshipPatch(patches).
+		Execute()
+	if err != nil {
+		return "", newRestError("patch relationships", resp, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 204 {
+		return "", newRestError("patch relationships", resp, nil)
+	}
+	return "", nil
+}
+
+func (b *restBackend) checkPermission(ctx context.Context, rel ketoClient.Relationship, maxDepth int64, consistency readConsistency) (bool, error) {
+	if consistency != (readConsistency{}) {
+		return false, errConsistencyNotSupported
+	}
+
+	request := b.readApiClient.PermissionApi.
+		CheckPermission(ctx).
+		Namespace(rel.Namespace).
+		Object(rel.Object).
+		Relation(rel.Relation)
+	if maxDepth > 0 {
+		request = request.MaxDepth(maxDepth)
+	}
+
+	if rel.SubjectId != nil {
+		request = request.SubjectId(*rel.SubjectId)
+	} else {
+		request = request.SubjectSetNamespace(rel.SubjectSet.Namespace).
+			SubjectSetObject(rel.SubjectSet.Object).
+			SubjectSetRelation(rel.SubjectSet.Relation)
+	}
+
+	result, resp, err := request.Execute()
+	if err != nil {
+		return false, newRestError("check permission", resp, err)
+	}
+	if resp.StatusCode != 200 {
+		return false, newRestError("check permission", resp, nil)
+	}
+	return result.GetAllowed(), nil
+}
+
+func (b *restBackend) expandPermissions(ctx context.Context, subjectSet ketoClient.SubjectSet, maxDepth int64, snaptoken string) (*ketoClient.ExpandedPermissionTree, error) {
+	if snaptoken != "" {
+		return nil, errConsistencyNotSupported
+	}
+
+	request := b.readApiClient.PermissionApi.
+		ExpandPermissions(ctx).
+		Namespace(subjectSet.Namespace).
+		Object(subjectSet.Object).
+		Relation(subjectSet.Relation)
+	if maxDepth > 0 {
+		request = request.MaxDepth(maxDepth)
+	}
+
+	tree, resp, err := request.Execute()
+	if err != nil {
+		return nil, newRestError("expand permissions", resp, err)
+	}
+	if resp.StatusCode != 200 {
+		return nil, newRestError("expand permissions", resp, nil)
+	}
+	return tree, nil
+}
+
+func (b *restBackend) checkOplSyntax(ctx context.Context, opl string) ([]ketoClient.ParseError, error) {
+	result, resp, err := b.oplApiClient.RelationshipApi.
+		CheckOplSyntax(ctx).
+		Body(opl).
+		Execute()
+	if err != nil {
+		return nil, newRestError("check OPL syntax", resp, err)
+	}
+	if resp.StatusCode != 200 {
+		return nil, newRestError("check OPL syntax", resp, nil)
+	}
+	return result.Errors, nil
+}
+
+func (b *restBackend) listNamespaces(ctx context.Context) ([]string, error) {
+	result, resp, err := b.readApiClient.RelationshipApi.
+		ListRelationshipNamespaces(ctx).
+		Execute()
+	if err != nil {
+		return nil, newRestError("list namespaces", resp, err)
+	}
+	if resp.StatusCode != 200 {
+		return nil, newRestError("list namespaces", resp, nil)
+	}
+
+	namespaces := make([]string, 0, len(result.Namespaces))
+	for _, namespace := range result.Namespaces {
+		namespaces = append(namespaces, namespace.GetName())
+	}
+	return namespaces, nil
+}
@@ -0,0 +1,313 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	ketoClient "github.com/ory/keto-client-go"
+	"github.com/ory/keto/ketoapi"
+	opl "github.com/ory/keto/proto/ory/keto/opl/v1alpha1"
+	rts "github.com/ory/keto/proto/ory/keto/relation_tuples/v1alpha2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+)
+
+// grpcBackend talks to Keto through the gRPC API, read connection serves the
+// read, check, expand and namespaces services, write connection serves the
+// write service and opl connection serves the syntax service.
+type grpcBackend struct {
+	readClient       rts.ReadServiceClient
+	checkClient      rts.CheckServiceClient
+	expandClient     rts.ExpandServiceClient
+	writeClient      rts.WriteServiceClient
+	syntaxClient     opl.SyntaxServiceClient
+	namespacesClient rts.NamespacesServiceClient
+}
+
+func newGrpcBackend(readConfig, writeConfig, oplConfig apiClientConfig) (*grpcBackend, error) {
+	readConn, err := newGrpcConn(readConfig)
+	if err != nil {
+		return nil, err
+	}
+	writeConn, err := newGrpcConn(writeConfig)
+	if err != nil {
+		return nil, err
+	}
+	oplConn, err := newGrpcConn(oplConfig)
+	if err != nil {
+		return nil, err
+	}
+	return &grpcBackend{
+		readClient:       rts.NewReadServiceClient(readConn),
+		checkClient:      rts.NewCheckServiceClient(readConn),
+		expandClient:     rts.NewExpandServiceClient(readConn),
+		writeClient:      rts.NewWriteServiceClient(writeConn),
+		syntaxClient:     opl.NewSyntaxServiceClient(oplConn),
+		namespacesClient: rts.NewNamespacesServiceClient(readConn),
+	}, nil
+}
+
+// newGrpcConn dials the target from the url, which is either plain host:port
+// or has a grpc(s) or http(s) scheme. TLS is used for grpcs and https schemes
+// or when any of the TLS settings is defined, headers are sent as metadata.
+func newGrpcConn(config apiClientConfig) (*grpc.ClientConn, error) {
+	target, useTLS, err := parseGrpcUrl(config.url)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig, err := newTLSConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	transportCredentials := insecure.NewCredentials()
+	if tlsConfig != nil {
+		transportCredentials = credentials.NewTLS(tlsConfig)
+	} else if useTLS {
+		transportCredentials = credentials.NewClientTLSFromCert(nil, "")
+	}
+
+	headers := make([]string, 0, len(config.headers)*2)
+	for k, v := range config.headers {
+		headers = append(headers, k, v)
+	}
+
+	return grpc.NewClient(
+		target,
+		grpc.WithTransportCredentials(transportCredentials),
+		grpc.WithUserAgent("terraform/ory-keto-provider"),
+		grpc.WithUnaryInterceptor(grpcUnaryInterceptor(headers, config.timeout)),
+	)
+}
+
+// grpcUnaryInterceptor attaches headers as outgoing metadata and applies the
+// request timeout to every call.
+func grpcUnaryInterceptor(headers []string, timeout time.Duration) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if len(headers) > 0 {
+			ctx = metadata.AppendToOutgoingContext(ctx, headers...)
+		}
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+func (b *grpcBackend) getRelationships(ctx context.Context, query ketoClient.RelationQuery, pageToken string, pageSize int64, snaptoken string) (*ketoClient.Relationships, error) {
+	resp, err := b.readClient.ListRelationTuples(ctx, &rts.ListRelationTuplesRequest{
+		RelationQuery: relationQueryToProto(query),
+		PageToken:     pageToken,
+		PageSize:      int32(pageSize),
+		Snaptoken:     snaptoken,
+	})
+	if err != nil {
+		return nil, newGrpcError("list relationships", err)
+	}
+
+	relationships := make([]ketoClient.Relationship, len(resp.RelationTuples))
+	for i, rt := range resp.RelationTuples {
+		relationships[i] = ketoRelationTupleToRelationship((&ketoapi.RelationTuple{}).FromProto(rt))
+	}
+	nextPageToken := resp.NextPageToken
+	return &ketoClient.Relationships{
+		RelationTuples: relationships,
+		NextPageToken:  &nextPageToken,
+	}, nil
+}
+
+func (b *grpcBackend) createRelationship(ctx context.Context, rel ketoClient.Relationship) (string, error) {
+	return b.patchRelationships(ctx, []ketoClient.RelationshipPatch{
+		newRelationshipPatch(relationshipPatchActionInsert, rel),
+	})
+}
+
+func (b *grpcBackend) deleteRelationships(ctx context.Context, query ketoClient.RelationQuery) error {
+	_, err := b.writeClient.DeleteRelationTuples(ctx, &rts.DeleteRelationTuplesRequest{
+		RelationQuery: relationQueryToProto(query),
+	})
+	if err != nil {
+		return newGrpcError("delete relationships", err)
+	}
+	return nil
+}
+
+func (b *grpcBackend) patchRelationships(ctx context.Context, patches []ketoClient.RelationshipPatch) (string, error) {
+	deltas := make([]*rts.RelationTupleDelta, len(patches))
+	for i, patch := range patches {
+		action := rts.RelationTupleDelta_ACTION_INSERT
+		switch patch.GetAction() {
+		case relationshipPatchActionInsert:
+		case relationshipPatchActionDelete:
+			action = rts.RelationTupleDelta_ACTION_DELETE
+		default:
+			return "", fmt.Errorf("unknown patch action %q", patch.GetAction())
+		}
+		deltas[i] = &rts.RelationTupleDelta{
+			Action:        action,
+			RelationTuple: ketoRelationshipToRelationTuple(patch.GetRelationTuple()).ToProto(),
+		}
+	}
+
+	resp, err := b.writeClient.TransactRelationTuples(ctx, &rts.TransactRelationTuplesRequest{
+		RelationTupleDeltas: deltas,
+	})
+	if err != nil {
+		return "", newGrpcError("patch relationships", err)
+	}
+
+	// deletes have an empty snaptoken, the last insert is the newest one
+	snaptoken := ""
+	for _, token := range resp.Snaptokens {
+		if token != "" {
+			snaptoken = token
+		}
+	}
+	return snaptoken, nil
+}
+
+// checkPermission relies on the check service reporting denied permissions
+// through the response, a PermissionDenied status is always an error from a
+// gateway in front of Keto.
+func (b *grpcBackend) checkPermission(ctx context.Context, rel ketoClient.Relationship, maxDepth int64, consistency readConsistency) (bool, error) {
+	resp, err := b.checkClient.Check(ctx, &rts.CheckRequest{
+		Tuple:     ketoRelationshipToRelationTuple(rel).ToProto(),
+		MaxDepth:  int32(maxDepth),
+		Snaptoken: consistency.snaptoken,
+		Latest:    consistency.latest,
+	})
+	if err != nil {
+		return false, newGrpcError("check permission", err)
+	}
+	return resp.Allowed, nil
+}
+
+func (b *grpcBackend) expandPermissions(ctx context.Context, subjectSet ketoClient.SubjectSet, maxDepth int64, snaptoken string) (*ketoClient.ExpandedPermissionTree, error) {
+	resp, err := b.expandClient.Expand(ctx, &rts.ExpandRequest{
+		Subject:   rts.NewSubjectSet(subjectSet.Namespace, subjectSet.Object, subjectSet.Relation),
+		MaxDepth:  int32(maxDepth),
+		Snaptoken: snaptoken,
+	})
+	if err != nil {
+		return nil, newGrpcError("expand permissions", err)
+	}
+	return subjectTreeFromProto(resp.Tree), nil
+}
+
+func (b *grpcBackend) checkOplSyntax(ctx context.Context, content string) ([]ketoClient.ParseError, error) {
+	resp, err := b.syntaxClient.Check(ctx, &opl.CheckRequest{
+		Content: []byte(content),
+	})
+	if err != nil {
+		return nil, newGrpcError("check OPL syntax", err)
+	}
+
+	parseErrors := make([]ketoClient.ParseError, len(resp.ParseErrors))
+	for i, parseError := range resp.ParseErrors {
+		message := parseError.Message
+		parseErrors[i] = ketoClient.ParseError{
+			Message: &message,
+			Start:   sourcePositionFromProto(parseError.Start),
+			End:     sourcePositionFromProto(parseError.End),
+		}
+	}
+	return parseErrors, nil
+}
+
+func sourcePositionFromProto(position *opl.SourcePosition) *ketoClient.SourcePosition {
+	if position == nil {
+		return nil
+	}
+	line := int64(position.Line)
+	column := int64(position.Column)
+	return &ketoClient.SourcePosition{
+		Line:   &line,
+		Column: &column,
+	}
+}
+
+func (b *grpcBackend) listNamespaces(ctx context.Context) ([]string, error) {
+	resp, err := b.namespacesClient.ListNamespaces(ctx, &rts.ListNamespacesRequest{})
+	if err != nil {
+		return nil, newGrpcError("list namespaces", err)
+	}
+
+	namespaces := make([]string, len(resp.Namespaces))
+	for i, namespace := range resp.Namespaces {
+		namespaces[i] = namespace.Name
+	}
+	return namespaces, nil
+}
+
+func relationQueryToProto(query ketoClient.RelationQuery) *rts.RelationQuery {
+	protoQuery := &rts.RelationQuery{
+		Namespace: query.Namespace,
+		Object:    query.Object,
+		Relation:  query.Relation,
+	}
+	if query.SubjectId != nil {
+		protoQuery.Subject = rts.NewSubjectID(*query.SubjectId)
+	} else if query.SubjectSet != nil {
+		protoQuery.Subject = rts.NewSubjectSet(query.SubjectSet.Namespace, query.SubjectSet.Object, query.SubjectSet.Relation)
+	}
+	return protoQuery
+}
+
+func subjectTreeFromProto(tree *rts.SubjectTree) *ketoClient.ExpandedPermissionTree {
+	if tree == nil {
+		return nil
+	}
+	expanded := &ketoClient.ExpandedPermissionTree{
+		Type:     ketoapi.TreeNodeType("").FromProto(tree.NodeType).String(),
+		Children: make([]ketoClient.ExpandedPermissionTree, 0, len(tree.Children)),
+	}
+	if tree.Tuple != nil {
+		rel := ketoRelationTupleToRelationship((&ketoapi.RelationTuple{}).FromProto(tree.Tuple))
+		expanded.Tuple = &rel
+	}
+	for _, child := range tree.Children {
+		expanded.Children = append(expanded.Children, *subjectTreeFromProto(child))
+	}
+	return expanded
+}
+
+// parseGrpcUrl returns the dial target and whether TLS was requested by the
+// url scheme. A url without port gets the default port of its scheme, 443
+// for grpcs and https and 80 for grpc and http.
+func parseGrpcUrl(rawUrl string) (string, bool, error) {
+	if !strings.Contains(rawUrl, "://") {
+		return rawUrl, false, nil
+	}
+	grpcUrl, err := url.Parse(rawUrl)
+	if err != nil {
+		return "", false, fmt.Errorf("parse keto url: %v", err)
+	}
+
+	var useTLS bool
+	defaultPort := "80"
+	switch grpcUrl.Scheme {
+	case "grpcs", "https":
+		useTLS = true
+		defaultPort = "443"
+	case "grpc", "http":
+	default:
+		return "", false, fmt.Errorf("unsupported keto grpc url scheme %q", grpcUrl.Scheme)
+	}
+	if grpcUrl.Hostname() == "" {
+		return "", false, fmt.Errorf("keto url %q has no host", rawUrl)
+	}
+
+	port := grpcUrl.Port()
+	if port == "" {
+		port = defaultPort
+	}
+	return net.JoinHostPort(grpcUrl.Hostname(), port), useTLS, nil
+}
@@ -0,0 +1,138 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	ketoClient "github.com/ory/keto-client-go"
+	rts "github.com/ory/keto/proto/ory/keto/relation_tuples/v1alpha2"
+	"google.golang.org/grpc"
+)
+
+func TestParseGrpcUrl(t *testing.T) {
+	tests := []struct {
+		name       string
+		url        string
+		wantTarget string
+		wantTLS    bool
+		wantErr    bool
+	}{
+		{name: "host and port", url: "localhost:4467", wantTarget: "localhost:4467"},
+		{name: "grpc", url: "grpc://keto:4467", wantTarget: "keto:4467"},
+		{name: "grpcs", url: "grpcs://keto:4467", wantTarget: "keto:4467", wantTLS: true},
+		{name: "https default port", url: "https://keto.example.com", wantTarget: "keto.example.com:443", wantTLS: true},
+		{name: "grpcs default port", url: "grpcs://keto.example.com", wantTarget: "keto.example.com:443", wantTLS: true},
+		{name: "http default port", url: "http://keto", wantTarget: "keto:80"},
+		{name: "grpc default port", url: "grpc://keto/", wantTarget: "keto:80"},
+		{name: "ipv6 default port", url: "https://[::1]", wantTarget: "[::1]:443", wantTLS: true},
+		{name: "ipv6", url: "grpc://[::1]:4467", wantTarget: "[::1]:4467"},
+		{name: "unsupported scheme", url: "ftp://keto:4467", wantErr: true},
+		{name: "no host", url: "https://", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, useTLS, err := parseGrpcUrl(tt.url)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+			if target != tt.wantTarget || useTLS != tt.wantTLS {
+				t.Fatalf("got %q tls %v, want %q tls %v", target, useTLS, tt.wantTarget, tt.wantTLS)
+			}
+		})
+	}
+}
+
+type fakeCheckClient struct {
+	rts.CheckServiceClient
+	request *rts.CheckRequest
+}
+
+func (c *fakeCheckClient) Check(ctx context.Context, in *rts.CheckRequest, opts ...grpc.CallOption) (*rts.CheckResponse, error) {
+	c.request = in
+	return &rts.CheckResponse{Allowed: true}, nil
+}
+
+type fakeReadClient struct {
+	rts.ReadServiceClient
+	request *rts.ListRelationTuplesRequest
+}
+
+func (c *fakeReadClient) ListRelationTuples(ctx context.Context, in *rts.ListRelationTuplesRequest, opts ...grpc.CallOption) (*rts.ListRelationTuplesResponse, error) {
+	c.request = in
+	return &rts.ListRelationTuplesResponse{}, nil
+}
+
+type fakeWriteClient struct {
+	rts.WriteServiceClient
+	snaptokens []string
+}
+
+func (c *fakeWriteClient) TransactRelationTuples(ctx context.Context, in *rts.TransactRelationTuplesRequest, opts ...grpc.CallOption) (*rts.TransactRelationTuplesResponse, error) {
+	return &rts.TransactRelationTuplesResponse{Snaptokens: c.snaptokens}, nil
+}
+
+func TestGrpcBackendReadConsistency(t *testing.T) {
+	tests := []struct {
+		name          string
+		consistency   readConsistency
+		wantSnaptoken string
+		wantLatest    bool
+	}{
+		{name: "server default"},
+		{name: "snaptoken", consistency: readConsistency{snaptoken: "token"}, wantSnaptoken: "token"},
+		{name: "latest", consistency: readConsistency{latest: true}, wantLatest: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkClient := &fakeCheckClient{}
+			readClient := &fakeReadClient{}
+			backend := &grpcBackend{checkClient: checkClient, readClient: readClient}
+
+			subjectId := "guest"
+			if _, err := backend.checkPermission(context.Background(), ketoClient.Relationship{
+				Namespace: "default",
+				Object:    "app",
+				Relation:  "read",
+				SubjectId: &subjectId,
+			}, 0, tt.consistency); err != nil {
+				t.Fatal(err)
+			}
+			if checkClient.request.Snaptoken != tt.wantSnaptoken || checkClient.request.Latest != tt.wantLatest {
+				t.Fatalf("got check snaptoken %q latest %v, want %q latest %v", checkClient.request.Snaptoken, checkClient.request.Latest, tt.wantSnaptoken, tt.wantLatest)
+			}
+
+			if _, err := backend.getRelationships(context.Background(), ketoClient.RelationQuery{}, "", 100, tt.consistency.snaptoken); err != nil {
+				t.Fatal(err)
+			}
+			if readClient.request.Snaptoken != tt.wantSnaptoken {
+				t.Fatalf("got list snaptoken %q, want %q", readClient.request.Snaptoken, tt.wantSnaptoken)
+			}
+		})
+	}
+}
+
+func TestGrpcBackendPatchSnaptoken(t *testing.T) {
+	tests := []struct {
+		name       string
+		snaptokens []string
+		want       string
+	}{
+		{name: "no snaptokens"},
+		{name: "single insert", snaptokens: []string{"a"}, want: "a"},
+		{name: "inserts", snaptokens: []string{"a", "b"}, want: "b"},
+		{name: "trailing delete", snaptokens: []string{"a", ""}, want: "a"},
+		{name: "only deletes", snaptokens: []string{"", ""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := &grpcBackend{writeClient: &fakeWriteClient{snaptokens: tt.snaptokens}}
+			got, err := backend.patchRelationships(context.Background(), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	ketoClient "github.com/ory/keto-client-go"
+)
+
+func TestRestBackendCheckPermission(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		body        string
+		wantAllowed bool
+		wantErr     bool
+	}{
+		{name: "allowed", status: http.StatusOK, body: `{"allowed":true}`, wantAllowed: true},
+		{name: "denied", status: http.StatusOK, body: `{"allowed":false}`},
+		{name: "gateway forbidden", status: http.StatusForbidden, body: `{"allowed":false}`, wantErr: true},
+		{name: "keto error", status: http.StatusBadRequest, body: `{"error":{"code":400,"status":"Bad Request","message":"unknown namespace"}}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var path string
+			provider := newTestProviderConfig(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				path = r.URL.Path
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+
+			subjectId := "guest"
+			allowed, err := provider.backend.checkPermission(context.Background(), ketoClient.Relationship{
+				Namespace: "default",
+				Object:    "app",
+				Relation:  "read",
+				SubjectId: &subjectId,
+			}, 0, readConsistency{})
+			if path != "/relation-tuples/check/openapi" {
+				t.Fatalf("got path %q, want the check endpoint that always answers 200", path)
+			}
+			var ketoErr *ketoError
+			if tt.wantErr != errors.As(err, &ketoErr) {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+			if allowed != tt.wantAllowed {
+				t.Fatalf("got allowed %v, want %v", allowed, tt.wantAllowed)
+			}
+		})
+	}
+}
+
+func TestRestBackendRejectsConsistency(t *testing.T) {
+	requests := 0
+	provider := newTestProviderConfig(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	subjectId := "guest"
+	rel := ketoClient.Relationship{Namespace: "default", Object: "app", Relation: "read", SubjectId: &subjectId}
+
+	tests := []struct {
+		name string
+		call func() error
+	}{
+		{"check snaptoken", func() error {
+			_, err := provider.backend.checkPermission(context.Background(), rel, 0, readConsistency{snaptoken: "token"})
+			return err
+		}},
+		{"check latest", func() error {
+			_, err := provider.backend.checkPermission(context.Background(), rel, 0, readConsistency{latest: true})
+			return err
+		}},
+		{"list snaptoken", func() error {
+			_, err := provider.backend.getRelationships(context.Background(), ketoClient.RelationQuery{}, "", 100, "token")
+			return err
+		}},
+		{"expand snaptoken", func() error {
+			_, err := provider.backend.expandPermissions(context.Background(), ketoClient.SubjectSet{Namespace: "default", Object: "app", Relation: "read"}, 0, "token")
+			return err
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.call(); !errors.Is(err, errConsistencyNotSupported) {
+				t.Fatalf("got %v, want %v", err, errConsistencyNotSupported)
+			}
+		})
+	}
+	if requests != 0 {
+		t.Fatalf("got %d requests, want the checks to fail before calling Keto", requests)
+	}
+}
@@ -0,0 +1,48 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	hash "github.com/theTardigrade/golang-hash"
+)
+
+func dataKetoNamespaces() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataKetoNamespacesRead,
+		Timeouts: &schema.ResourceTimeout{
+			Read: schema.DefaultTimeout(5 * time.Minute),
+		},
+		Schema: map[string]*schema.Schema{
+			"names": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+		},
+	}
+}
+
+func dataKetoNamespacesRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	provider := m.(*providerConfig)
+
+	namespaces, err := provider.backend.listNamespaces(ctx)
+	if err != nil {
+		return ketoDiagnostics(err, nil)
+	}
+	sort.Strings(namespaces)
+
+	if err := d.Set("names", namespaces); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(fmt.Sprintf("%x", hash.UintString(strings.Join(namespaces, ","))))
+	return nil
+}
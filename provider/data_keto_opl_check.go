@@ -0,0 +1,115 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	ketoClient "github.com/ory/keto-client-go"
+	hash "github.com/theTardigrade/golang-hash"
+)
+
+func dataKetoOplCheck() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataKetoOplCheckRead,
+		Timeouts: &schema.ResourceTimeout{
+			Read: schema.DefaultTimeout(5 * time.Minute),
+		},
+		Schema: map[string]*schema.Schema{
+			"opl": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"fail_on_error": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+			"valid": {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+			"errors": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"message": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"start_line": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"start_column": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"end_line": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"end_column": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataKetoOplCheckRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	provider := m.(*providerConfig)
+
+	opl := d.Get("opl").(string)
+	parseErrors, err := provider.backend.checkOplSyntax(ctx, opl)
+	if err != nil {
+		return ketoDiagnostics(err, cty.GetAttrPath("opl"))
+	}
+
+	if d.Get("fail_on_error").(bool) && len(parseErrors) > 0 {
+		diags := make(diag.Diagnostics, len(parseErrors))
+		for i, parseError := range parseErrors {
+			start := parseError.GetStart()
+			diags[i] = diag.Diagnostic{
+				Severity:      diag.Error,
+				Summary:       "Ory Permission Language syntax error",
+				Detail:        fmt.Sprintf("line %d, column %d: %s", start.GetLine(), start.GetColumn(), parseError.GetMessage()),
+				AttributePath: cty.GetAttrPath("opl"),
+			}
+		}
+		return diags
+	}
+
+	if err := d.Set("valid", len(parseErrors) == 0); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("errors", flattenParseErrors(parseErrors)); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(fmt.Sprintf("%x", hash.UintString(opl)))
+	return nil
+}
+
+func flattenParseErrors(parseErrors []ketoClient.ParseError) []interface{} {
+	flatten := make([]interface{}, len(parseErrors))
+	for i, parseError := range parseErrors {
+		start := parseError.GetStart()
+		end := parseError.GetEnd()
+		flatten[i] = map[string]interface{}{
+			"message":      parseError.GetMessage(),
+			"start_line":   int(start.GetLine()),
+			"start_column": int(start.GetColumn()),
+			"end_line":     int(end.GetLine()),
+			"end_column":   int(end.GetColumn()),
+		}
+	}
+	return flatten
+}
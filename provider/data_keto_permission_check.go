@@ -4,102 +4,155 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"time"
 
-	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/datasource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework-validators/datasourcevalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	dschema "github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 	hash "github.com/theTardigrade/golang-hash"
 )
 
-func dataKetoPermissionCheck() *schema.Resource {
-	return &schema.Resource{
-		ReadContext: dataKetoPermissionCheckRead,
-		Schema: map[string]*schema.Schema{
-			"namespace": {
-				Type:     schema.TypeString,
-				Optional: true,
-				ForceNew: true,
-			},
-			"object": {
-				Type:     schema.TypeString,
-				Optional: true,
-				ForceNew: true,
-			},
-			"relation": {
-				Type:     schema.TypeString,
-				Optional: true,
-				ForceNew: true,
-			},
-			"subject_id": {
-				Type:     schema.TypeString,
-				Optional: true,
-				ForceNew: true,
-			},
-			"subject_set_namespace": {
-				Type:     schema.TypeString,
-				ForceNew: true,
-				Optional: true,
-			},
-			"subject_set_object": {
-				Type:     schema.TypeString,
-				ForceNew: true,
-				Optional: true,
-			},
-			"subject_set_relation": {
-				Type:     schema.TypeString,
-				ForceNew: true,
-				Optional: true,
-			},
-			"allowed": {
-				Type:     schema.TypeBool,
-				Computed: true,
-			},
+var (
+	_ datasource.DataSourceWithConfigure        = &permissionCheckDataSource{}
+	_ datasource.DataSourceWithConfigValidators = &permissionCheckDataSource{}
+)
+
+type permissionCheckDataSource struct {
+	provider *providerConfig
+}
+
+type permissionCheckDataSourceModel struct {
+	Id            types.String   `tfsdk:"id"`
+	Namespace     types.String   `tfsdk:"namespace"`
+	Object        types.String   `tfsdk:"object"`
+	Relation      types.String   `tfsdk:"relation"`
+	SubjectId     types.String   `tfsdk:"subject_id"`
+	SubjectSet    types.Object   `tfsdk:"subject_set"`
+	MaxDepth      types.Int64    `tfsdk:"max_depth"`
+	Snaptoken     types.String   `tfsdk:"snaptoken"`
+	Consistency   types.String   `tfsdk:"consistency"`
+	ExpectAllowed types.Bool     `tfsdk:"expect_allowed"`
+	Allowed       types.Bool     `tfsdk:"allowed"`
+	Timeouts      timeouts.Value `tfsdk:"timeouts"`
+}
+
+func newPermissionCheckDataSource() datasource.DataSource {
+	return &permissionCheckDataSource{}
+}
+
+func (d *permissionCheckDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_permission_check"
+}
+
+func (d *permissionCheckDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	attributes := tupleDataSourceAttributes()
+	attributes["id"] = dschema.StringAttribute{
+		Computed: true,
+	}
+	attributes["max_depth"] = dschema.Int64Attribute{
+		Optional: true,
+		Validators: []validator.Int64{
+			int64validator.AtLeast(1),
+		},
+	}
+	attributes["snaptoken"] = dschema.StringAttribute{
+		Optional: true,
+	}
+	attributes["consistency"] = dschema.StringAttribute{
+		Optional: true,
+		Validators: []validator.String{
+			stringvalidator.OneOf(consistencyLatest),
+		},
+	}
+	attributes["expect_allowed"] = dschema.BoolAttribute{
+		Optional: true,
+	}
+	attributes["allowed"] = dschema.BoolAttribute{
+		Computed: true,
+	}
+	resp.Schema = dschema.Schema{
+		Attributes: attributes,
+		Blocks: map[string]dschema.Block{
+			"timeouts": timeouts.Block(ctx),
 		},
 	}
 }
 
-func dataKetoPermissionCheckRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	provider := m.(*providerConfig)
+func (d *permissionCheckDataSource) ConfigValidators(ctx context.Context) []datasource.ConfigValidator {
+	return []datasource.ConfigValidator{
+		datasourcevalidator.Conflicting(
+			path.MatchRoot("snaptoken"),
+			path.MatchRoot("consistency"),
+		),
+	}
+}
 
-	if err := validateSchemaRelationTuple(d, ""); err != nil {
-		return diag.FromErr(err)
+func (d *permissionCheckDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
 	}
+	d.provider = req.ProviderData.(*providerConfig)
+}
 
-	rel, err := getClientRelationship(d)
-	if err != nil {
-		return diag.FromErr(err)
+func (d *permissionCheckDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var model permissionCheckDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &model)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	timeout, diags := model.Timeouts.Read(ctx, 5*time.Minute)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	rel := tupleModel{
+		Namespace:  model.Namespace,
+		Object:     model.Object,
+		Relation:   model.Relation,
+		SubjectId:  model.SubjectId,
+		SubjectSet: model.SubjectSet,
+	}.relationship()
 
 	relJson, err := json.Marshal(rel)
 	if err != nil {
-		return diag.FromErr(err)
+		resp.Diagnostics.AddError(err.Error(), "")
+		return
 	}
 
-	request := provider.readApiClient.PermissionApi.
-		CheckPermission(ctx).
-		Namespace(rel.Namespace).
-		Object(rel.Object).
-		Relation(rel.Relation)
-
-	if rel.SubjectId != nil {
-		request = request.SubjectId(*rel.SubjectId)
-	} else {
-		request = request.SubjectSetNamespace(rel.SubjectSet.Namespace).
-			SubjectSetObject(rel.SubjectSet.Object).
-			SubjectSetRelation(rel.SubjectSet.Relation)
+	consistency := readConsistency{
+		snaptoken: model.Snaptoken.ValueString(),
+		latest:    model.Consistency.ValueString() == consistencyLatest,
 	}
-
-	result, resp, err := request.Execute()
+	allowed, err := d.provider.backend.checkPermission(ctx, rel, model.MaxDepth.ValueInt64(), consistency)
 	if err != nil {
-		return diag.FromErr(err)
-	}
-	if resp.StatusCode != 200 {
-		return diag.FromErr(err)
+		resp.Diagnostics.Append(ketoFrameworkDiagnostics(err, path.Empty())...)
+		return
 	}
 
-	if err := d.Set("allowed", result.GetAllowed()); err != nil {
-		return diag.FromErr(err)
+	if !model.ExpectAllowed.IsNull() && model.ExpectAllowed.ValueBool() != allowed {
+		outcome := "denied"
+		if allowed {
+			outcome = "allowed"
+		}
+		resp.Diagnostics.AddAttributeError(
+			path.Root("expect_allowed"),
+			"Permission check assertion failed",
+			fmt.Sprintf("%s was %s, expect_allowed is %t", ketoRelationshipToRelationTuple(rel).String(), outcome, model.ExpectAllowed.ValueBool()),
+		)
+		return
 	}
 
-	d.SetId(fmt.Sprintf("%x", hash.UintString(string(relJson))))
-	return nil
+	model.Allowed = types.BoolValue(allowed)
+	model.Id = types.StringValue(fmt.Sprintf("%x", hash.UintString(string(relJson))))
+	resp.Diagnostics.Append(resp.State.Set(ctx, model)...)
 }
@@ -0,0 +1,149 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	hash "github.com/theTardigrade/golang-hash"
+)
+
+func dataKetoPermissionChecks() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataKetoPermissionChecksRead,
+		Timeouts: &schema.ResourceTimeout{
+			Read: schema.DefaultTimeout(5 * time.Minute),
+		},
+		Schema: map[string]*schema.Schema{
+			"checks": {
+				Type:     schema.TypeMap,
+				Required: true,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validateRelationTupleString,
+				},
+			},
+			"concurrency": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      10,
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"max_depth": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"snaptoken": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"consistency"},
+			},
+			"consistency": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice([]string{consistencyLatest}, false),
+			},
+			"results": {
+				Type:     schema.TypeMap,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeBool,
+				},
+			},
+		},
+	}
+}
+
+func dataKetoPermissionChecksRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	provider := m.(*providerConfig)
+
+	checks := d.Get("checks").(map[string]interface{})
+	results, err := checkPermissions(ctx, provider, checks, int64(d.Get("max_depth").(int)), getReadConsistency(d), d.Get("concurrency").(int))
+	if err != nil {
+		return ketoDiagnostics(err, cty.GetAttrPath("checks"))
+	}
+
+	if err := d.Set("results", results); err != nil {
+		return diag.FromErr(err)
+	}
+
+	checksJson, err := json.Marshal(checks)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId(fmt.Sprintf("%x", hash.UintString(string(checksJson))))
+	return nil
+}
+
+// checkPermissions runs the checks concurrently with at most concurrency
+// requests in flight, the first failed check cancels the remaining ones.
+func checkPermissions(ctx context.Context, provider *providerConfig, checks map[string]interface{}, maxDepth int64, consistency readConsistency, concurrency int) (map[string]bool, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	results := make(map[string]bool, len(checks))
+	semaphore := make(chan struct{}, concurrency)
+
+	for key, raw := range checks {
+		rt, err := stringToRelationTuple(raw.(string))
+		if err != nil {
+			return nil, fmt.Errorf("check %q: %v", key, err)
+		}
+		rel := ketoRelationTupleToRelationship(rt)
+
+		select {
+		case semaphore <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(key string) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			allowed, err := provider.backend.checkPermission(ctx, rel, maxDepth, consistency)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = fmt.Errorf("check %q: %w", key, err)
+					cancel()
+				}
+				return
+			}
+			results[key] = allowed
+		}(key)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func getReadConsistency(d *schema.ResourceData) readConsistency {
+	return readConsistency{
+		snaptoken: d.Get("snaptoken").(string),
+		latest:    d.Get("consistency").(string) == consistencyLatest,
+	}
+}
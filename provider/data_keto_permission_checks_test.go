@@ -0,0 +1,88 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCheckPermissions(t *testing.T) {
+	checks := make(map[string]interface{})
+	want := make(map[string]bool)
+	for i := 0; i < 12; i++ {
+		key := fmt.Sprintf("check_%d", i)
+		// even users are allowed, so a result stored under the wrong key shows
+		checks[key] = fmt.Sprintf("default:app#read@user%d", i)
+		want[key] = i%2 == 0
+	}
+
+	tests := []struct {
+		name        string
+		concurrency int
+		deny        string
+		wantErr     string
+	}{
+		{name: "sequential", concurrency: 1},
+		{name: "bounded", concurrency: 3},
+		{name: "more workers than checks", concurrency: 20},
+		{name: "failed check", concurrency: 3, deny: "user5", wantErr: `check "check_5"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				mu          sync.Mutex
+				inFlight    int
+				maxInFlight int
+			)
+			provider := newTestProviderConfig(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				inFlight++
+				if inFlight > maxInFlight {
+					maxInFlight = inFlight
+				}
+				mu.Unlock()
+				defer func() {
+					mu.Lock()
+					inFlight--
+					mu.Unlock()
+				}()
+				time.Sleep(5 * time.Millisecond)
+
+				subjectId := r.URL.Query().Get("subject_id")
+				w.Header().Set("Content-Type", "application/json")
+				if subjectId == tt.deny {
+					w.WriteHeader(http.StatusBadRequest)
+					_, _ = w.Write([]byte(`{"error":{"code":400,"status":"Bad Request","message":"invalid subject"}}`))
+					return
+				}
+				var i int
+				_, _ = fmt.Sscanf(subjectId, "user%d", &i)
+				_, _ = fmt.Fprintf(w, `{"allowed":%v}`, i%2 == 0)
+			}))
+
+			results, err := checkPermissions(context.Background(), provider, checks, 0, readConsistency{}, tt.concurrency)
+			mu.Lock()
+			defer mu.Unlock()
+			if maxInFlight > tt.concurrency {
+				t.Fatalf("got %d checks in flight, want at most %d", maxInFlight, tt.concurrency)
+			}
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got error %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(results, want) {
+				t.Fatalf("got results %v, want %v", results, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	ketoClient "github.com/ory/keto-client-go"
+	hash "github.com/theTardigrade/golang-hash"
+)
+
+func dataKetoPermissionExpand() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataKetoPermissionExpandRead,
+		Timeouts: &schema.ResourceTimeout{
+			Read: schema.DefaultTimeout(5 * time.Minute),
+		},
+		Schema: map[string]*schema.Schema{
+			"namespace": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"object": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"relation": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"max_depth": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"snaptoken": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"tree": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"type": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"depth": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"parent": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"namespace": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"object": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"relation": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"subject_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"subject_set_namespace": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"subject_set_object": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"subject_set_relation": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+			"tree_json": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"subject_ids": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+		},
+	}
+}
+
+func dataKetoPermissionExpandRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	provider := m.(*providerConfig)
+
+	namespace := d.Get("namespace").(string)
+	object := d.Get("object").(string)
+	relation := d.Get("relation").(string)
+
+	subjectSet := ketoClient.SubjectSet{
+		Namespace: namespace,
+		Object:    object,
+		Relation:  relation,
+	}
+	tree, err := provider.backend.expandPermissions(ctx, subjectSet, int64(d.Get("max_depth").(int)), d.Get("snaptoken").(string))
+	if err != nil {
+		return ketoDiagnostics(err, nil)
+	}
+
+	treeJson, err := json.Marshal(tree)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := d.Set("tree", flattenExpandedPermissionTree(tree, 0, -1, nil)); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("tree_json", string(treeJson)); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("subject_ids", expandedPermissionTreeSubjectIds(tree)); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(fmt.Sprintf("%x", hash.UintString(fmt.Sprintf("%s:%s#%s", namespace, object, relation)+string(treeJson))))
+	return nil
+}
+
+// flattenExpandedPermissionTree walks the tree depth first, every node holds
+// the index of its parent node in the returned list, root has parent -1.
+func flattenExpandedPermissionTree(tree *ketoClient.ExpandedPermissionTree, depth, parent int, flatten []interface{}) []interface{} {
+	if tree == nil {
+		return flatten
+	}
+	node := map[string]interface{}{
+		"type":   tree.Type,
+		"depth":  depth,
+		"parent": parent,
+	}
+	if tree.Tuple != nil {
+		node["namespace"] = tree.Tuple.Namespace
+		node["object"] = tree.Tuple.Object
+		node["relation"] = tree.Tuple.Relation
+		if tree.Tuple.SubjectId != nil {
+			node["subject_id"] = *tree.Tuple.SubjectId
+		} else if tree.Tuple.SubjectSet != nil {
+			node["subject_set_namespace"] = tree.Tuple.SubjectSet.Namespace
+			node["subject_set_object"] = tree.Tuple.SubjectSet.Object
+			node["subject_set_relation"] = tree.Tuple.SubjectSet.Relation
+		}
+	}
+
+	index := len(flatten)
+	flatten = append(flatten, node)
+	for i := range tree.Children {
+		flatten = flattenExpandedPermissionTree(&tree.Children[i], depth+1, index, flatten)
+	}
+	return flatten
+}
+
+// expandedPermissionTreeSubjectIds returns sorted unique subject IDs of all
+// leaf nodes in the tree.
+func expandedPermissionTreeSubjectIds(tree *ketoClient.ExpandedPermissionTree) []string {
+	keys := make(map[string]bool)
+	var walk func(t *ketoClient.ExpandedPermissionTree)
+	walk = func(t *ketoClient.ExpandedPermissionTree) {
+		if t.Type == "leaf" && t.Tuple != nil && t.Tuple.SubjectId != nil {
+			keys[*t.Tuple.SubjectId] = true
+		}
+		for i := range t.Children {
+			walk(&t.Children[i])
+		}
+	}
+	if tree != nil {
+		walk(tree)
+	}
+
+	subjectIds := make([]string, 0, len(keys))
+	for k := range keys {
+		subjectIds = append(subjectIds, k)
+	}
+	sort.Strings(subjectIds)
+	return subjectIds
+}
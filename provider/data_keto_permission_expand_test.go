@@ -0,0 +1,159 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+
+	ketoClient "github.com/ory/keto-client-go"
+)
+
+func TestPermissionExpandMaxDepthValidation(t *testing.T) {
+	tests := []struct {
+		maxDepth int
+		wantErr  bool
+	}{
+		{maxDepth: -1, wantErr: true},
+		{maxDepth: 0, wantErr: true},
+		{maxDepth: 1},
+		{maxDepth: 5},
+	}
+	validate := dataKetoPermissionExpand().Schema["max_depth"].ValidateFunc
+	for _, tt := range tests {
+		_, errs := validate(tt.maxDepth, "max_depth")
+		if (len(errs) > 0) != tt.wantErr {
+			t.Errorf("max_depth %d: got errors %v, want error %v", tt.maxDepth, errs, tt.wantErr)
+		}
+	}
+}
+
+func TestFlattenExpandedPermissionTree(t *testing.T) {
+	subjectId := func(id string) *string { return &id }
+	leaf := func(id string) ketoClient.ExpandedPermissionTree {
+		return ketoClient.ExpandedPermissionTree{
+			Type:  "leaf",
+			Tuple: &ketoClient.Relationship{Namespace: "groups", Object: "admins", Relation: "member", SubjectId: subjectId(id)},
+		}
+	}
+	tree := &ketoClient.ExpandedPermissionTree{
+		Type: "union",
+		Tuple: &ketoClient.Relationship{
+			Namespace:  "default",
+			Object:     "app",
+			Relation:   "write",
+			SubjectSet: &ketoClient.SubjectSet{Namespace: "default", Object: "app", Relation: "write"},
+		},
+		Children: []ketoClient.ExpandedPermissionTree{
+			{
+				Type: "union",
+				Tuple: &ketoClient.Relationship{
+					Namespace:  "default",
+					Object:     "app",
+					Relation:   "write",
+					SubjectSet: &ketoClient.SubjectSet{Namespace: "groups", Object: "admins", Relation: "member"},
+				},
+				Children: []ketoClient.ExpandedPermissionTree{leaf("alice"), leaf("bob")},
+			},
+			{
+				Type:  "leaf",
+				Tuple: &ketoClient.Relationship{Namespace: "default", Object: "app", Relation: "write", SubjectId: subjectId("bob")},
+			},
+			{Type: "unspecified"},
+		},
+	}
+
+	tests := []struct {
+		name           string
+		tree           *ketoClient.ExpandedPermissionTree
+		wantParents    []int
+		wantDepths     []int
+		wantSubjectIds []string
+	}{
+		{
+			name:           "empty",
+			wantSubjectIds: []string{},
+		},
+		{
+			name:           "single leaf",
+			tree:           &ketoClient.ExpandedPermissionTree{Type: "leaf", Tuple: &ketoClient.Relationship{SubjectId: subjectId("alice")}},
+			wantParents:    []int{-1},
+			wantDepths:     []int{0},
+			wantSubjectIds: []string{"alice"},
+		},
+		{
+			name:           "nested",
+			tree:           tree,
+			wantParents:    []int{-1, 0, 1, 1, 0, 0},
+			wantDepths:     []int{0, 1, 2, 2, 1, 1},
+			wantSubjectIds: []string{"alice", "bob"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var parents, depths []int
+			for _, node := range flattenExpandedPermissionTree(tt.tree, 0, -1, nil) {
+				parents = append(parents, node.(map[string]interface{})["parent"].(int))
+				depths = append(depths, node.(map[string]interface{})["depth"].(int))
+			}
+			if !reflect.DeepEqual(parents, tt.wantParents) {
+				t.Errorf("got parents %v, want %v", parents, tt.wantParents)
+			}
+			if !reflect.DeepEqual(depths, tt.wantDepths) {
+				t.Errorf("got depths %v, want %v", depths, tt.wantDepths)
+			}
+			if got := expandedPermissionTreeSubjectIds(tt.tree); !reflect.DeepEqual(got, tt.wantSubjectIds) {
+				t.Errorf("got subject ids %v, want %v", got, tt.wantSubjectIds)
+			}
+		})
+	}
+}
+
+func TestFlattenExpandedPermissionTreeNode(t *testing.T) {
+	subjectId := "alice"
+	tests := []struct {
+		name string
+		tree *ketoClient.ExpandedPermissionTree
+		want map[string]interface{}
+	}{
+		{
+			name: "subject id",
+			tree: &ketoClient.ExpandedPermissionTree{
+				Type:  "leaf",
+				Tuple: &ketoClient.Relationship{Namespace: "default", Object: "app", Relation: "read", SubjectId: &subjectId},
+			},
+			want: map[string]interface{}{
+				"type": "leaf", "depth": 0, "parent": -1,
+				"namespace": "default", "object": "app", "relation": "read", "subject_id": "alice",
+			},
+		},
+		{
+			name: "subject set",
+			tree: &ketoClient.ExpandedPermissionTree{
+				Type: "union",
+				Tuple: &ketoClient.Relationship{
+					Namespace:  "default",
+					Object:     "app",
+					Relation:   "read",
+					SubjectSet: &ketoClient.SubjectSet{Namespace: "groups", Object: "admins", Relation: "member"},
+				},
+			},
+			want: map[string]interface{}{
+				"type": "union", "depth": 0, "parent": -1,
+				"namespace": "default", "object": "app", "relation": "read",
+				"subject_set_namespace": "groups", "subject_set_object": "admins", "subject_set_relation": "member",
+			},
+		},
+		{
+			name: "no tuple",
+			tree: &ketoClient.ExpandedPermissionTree{Type: "unspecified"},
+			want: map[string]interface{}{"type": "unspecified", "depth": 0, "parent": -1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := flattenExpandedPermissionTree(tt.tree, 0, -1, nil)
+			if len(got) != 1 || !reflect.DeepEqual(got[0], tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
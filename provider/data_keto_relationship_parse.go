@@ -2,136 +2,98 @@ package provider
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"strings"
 
-	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	dschema "github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/ory/keto/ketoapi"
 	hash "github.com/theTardigrade/golang-hash"
 )
 
-func dataKetoRelationshipParse() *schema.Resource {
-	return &schema.Resource{
-		ReadContext: dataKetoRelationshipParseRead,
-		Schema: map[string]*schema.Schema{
-			"from_string": {
-				Type:     schema.TypeString,
+var _ datasource.DataSource = &relationshipParseDataSource{}
+
+type relationshipParseDataSource struct{}
+
+type relationshipParseDataSourceModel struct {
+	Id            types.String `tfsdk:"id"`
+	FromString    types.String `tfsdk:"from_string"`
+	RelationTuple types.List   `tfsdk:"relation_tuple"`
+	Json          types.List   `tfsdk:"json"`
+}
+
+func newRelationshipParseDataSource() datasource.DataSource {
+	return &relationshipParseDataSource{}
+}
+
+func (d *relationshipParseDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_relationship_parse"
+}
+
+func (d *relationshipParseDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = dschema.Schema{
+		Attributes: map[string]dschema.Attribute{
+			"id": dschema.StringAttribute{
+				Computed: true,
+			},
+			"from_string": dschema.StringAttribute{
 				Required: true,
 			},
-			"relation_tuple": {
-				Type:     schema.TypeList,
+			"relation_tuple": dschema.ListNestedAttribute{
 				Computed: true,
-				Elem: &schema.Resource{
-					Schema: map[string]*schema.Schema{
-						"namespace": {
-							Type:     schema.TypeString,
-							Computed: true,
-						},
-						"object": {
-							Type:     schema.TypeString,
-							Computed: true,
-						},
-						"relation": {
-							Type:     schema.TypeString,
-							Computed: true,
-						},
-						"subject_id": {
-							Type:     schema.TypeString,
-							Computed: true,
-						},
-						"subject_set_namespace": {
-							Type:     schema.TypeString,
-							Computed: true,
-						},
-						"subject_set_object": {
-							Type:     schema.TypeString,
-							Computed: true,
-						},
-						"subject_set_relation": {
-							Type:     schema.TypeString,
-							Computed: true,
-						},
-					},
+				NestedObject: dschema.NestedAttributeObject{
+					Attributes: tupleComputedDataSourceAttributes(),
 				},
 			},
-			"json": {
-				Type:     schema.TypeList,
-				Computed: true,
-				Elem: &schema.Schema{
-					Type: schema.TypeString,
-				},
+			"json": dschema.ListAttribute{
+				Computed:    true,
+				ElementType: types.StringType,
 			},
 		},
 	}
 }
 
-func dataKetoRelationshipParseRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	var relationshipTuples []*ketoapi.RelationTuple
-	fromString := d.Get("from_string").(string)
-	for _, relString := range strings.Split(fromString, "\n") {
-		cleanRelString := strings.TrimSpace(relString)
-		if cleanRelString == "" {
-			continue
-		}
-		rt, err := stringToRelationTuple(cleanRelString)
-		if err != nil {
-			return diag.FromErr(err)
-		}
-		relationshipTuples = append(relationshipTuples, rt)
+func (d *relationshipParseDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var model relationshipParseDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &model)...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
-	jsonValue, err := flattenRelationTupleToJsonList(relationshipTuples)
+	fromString := model.FromString.ValueString()
+	relationshipTuples, err := stringToRelationTuples(fromString)
 	if err != nil {
-		return diag.FromErr(err)
+		resp.Diagnostics.AddAttributeError(path.Root("from_string"), err.Error(), "")
+		return
 	}
 
-	if err := d.Set("json", jsonValue); err != nil {
-		return diag.FromErr(err)
+	jsonValue, err := flattenRelationTupleToJsonList(relationshipTuples)
+	if err != nil {
+		resp.Diagnostics.AddError(err.Error(), "")
+		return
 	}
 
-	if err := d.Set("relation_tuple", flattenRelationTuple(relationshipTuples)); err != nil {
-		return diag.FromErr(err)
+	var diags diag.Diagnostics
+	model.Json, diags = types.ListValueFrom(ctx, types.StringType, jsonValue)
+	resp.Diagnostics.Append(diags...)
+	model.RelationTuple, diags = relationTupleListValue(ctx, relationshipTuples)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
-	d.SetId(fmt.Sprintf("%x", hash.UintString(fromString)))
-	return nil
-}
-
-func stringToRelationTuple(s string) (*ketoapi.RelationTuple, error) {
-	return (&ketoapi.RelationTuple{}).FromString(s)
-}
-
-func flattenRelationTuple(rt []*ketoapi.RelationTuple) []interface{} {
-	flatten := make([]interface{}, len(rt))
-	for i, rt := range rt {
-		m := map[string]interface{}{
-			"namespace": rt.Namespace,
-			"object":    rt.Object,
-			"relation":  rt.Relation,
-		}
-		if rt.SubjectID != nil {
-			m["subject_id"] = *rt.SubjectID
-		} else {
-			m["subject_set_namespace"] = rt.SubjectSet.Namespace
-			m["subject_set_object"] = rt.SubjectSet.Object
-			m["subject_set_relation"] = rt.SubjectSet.Relation
-		}
-
-		flatten[i] = m
-	}
-	return flatten
+	model.Id = types.StringValue(fmt.Sprintf("%x", hash.UintString(fromString)))
+	resp.Diagnostics.Append(resp.State.Set(ctx, model)...)
 }
 
-func flattenRelationTupleToJsonList(rt []*ketoapi.RelationTuple) ([]string, error) {
-	flatten := make([]string, len(rt))
-	for i, rt := range rt {
-		b, err := json.Marshal(rt)
-		if err != nil {
-			return nil, err
-		}
-		flatten[i] = string(b)
+// relationTupleListValue returns the relation_tuple list of the tuples, the
+// subject attributes that don't apply to a tuple are null.
+func relationTupleListValue(ctx context.Context, rts []*ketoapi.RelationTuple) (types.List, diag.Diagnostics) {
+	tuples := make([]tupleModel, len(rts))
+	for i, rt := range rts {
+		tuples[i] = tupleModelFrom(ketoRelationTupleToRelationship(rt))
 	}
-	return flatten, nil
+	return types.ListValueFrom(ctx, types.ObjectType{AttrTypes: tupleAttrTypes}, tuples)
 }
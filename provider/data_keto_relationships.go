@@ -0,0 +1,170 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/datasource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework-validators/datasourcevalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	dschema "github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	ketoClient "github.com/ory/keto-client-go"
+	"github.com/ory/keto/ketoapi"
+	hash "github.com/theTardigrade/golang-hash"
+)
+
+var (
+	_ datasource.DataSourceWithConfigure        = &relationshipsDataSource{}
+	_ datasource.DataSourceWithConfigValidators = &relationshipsDataSource{}
+)
+
+type relationshipsDataSource struct {
+	provider *providerConfig
+}
+
+type relationshipsDataSourceModel struct {
+	Id            types.String   `tfsdk:"id"`
+	Namespace     types.String   `tfsdk:"namespace"`
+	Object        types.String   `tfsdk:"object"`
+	Relation      types.String   `tfsdk:"relation"`
+	SubjectId     types.String   `tfsdk:"subject_id"`
+	SubjectSet    types.Object   `tfsdk:"subject_set"`
+	Snaptoken     types.String   `tfsdk:"snaptoken"`
+	RelationTuple types.List     `tfsdk:"relation_tuple"`
+	Tuples        types.List     `tfsdk:"tuples"`
+	Timeouts      timeouts.Value `tfsdk:"timeouts"`
+}
+
+func newRelationshipsDataSource() datasource.DataSource {
+	return &relationshipsDataSource{}
+}
+
+func (d *relationshipsDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_relationships"
+}
+
+func (d *relationshipsDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = dschema.Schema{
+		Attributes: map[string]dschema.Attribute{
+			"id": dschema.StringAttribute{
+				Computed: true,
+			},
+			"namespace": dschema.StringAttribute{
+				Optional: true,
+			},
+			"object": dschema.StringAttribute{
+				Optional: true,
+			},
+			"relation": dschema.StringAttribute{
+				Optional: true,
+			},
+			"subject_id": dschema.StringAttribute{
+				Optional: true,
+			},
+			"subject_set": dschema.SingleNestedAttribute{
+				Optional: true,
+				Attributes: map[string]dschema.Attribute{
+					"namespace": dschema.StringAttribute{
+						Required: true,
+					},
+					"object": dschema.StringAttribute{
+						Required: true,
+					},
+					"relation": dschema.StringAttribute{
+						Optional: true,
+					},
+				},
+			},
+			"snaptoken": dschema.StringAttribute{
+				Optional: true,
+			},
+			"relation_tuple": dschema.ListNestedAttribute{
+				Computed: true,
+				NestedObject: dschema.NestedAttributeObject{
+					Attributes: tupleComputedDataSourceAttributes(),
+				},
+			},
+			"tuples": dschema.ListAttribute{
+				Computed:    true,
+				ElementType: types.StringType,
+			},
+		},
+		Blocks: map[string]dschema.Block{
+			"timeouts": timeouts.Block(ctx),
+		},
+	}
+}
+
+func (d *relationshipsDataSource) ConfigValidators(ctx context.Context) []datasource.ConfigValidator {
+	return []datasource.ConfigValidator{
+		datasourcevalidator.Conflicting(
+			path.MatchRoot("subject_id"),
+			path.MatchRoot("subject_set"),
+		),
+	}
+}
+
+func (d *relationshipsDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	d.provider = req.ProviderData.(*providerConfig)
+}
+
+func (d *relationshipsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var model relationshipsDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &model)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	timeout, diags := model.Timeouts.Read(ctx, 5*time.Minute)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	// a subject set without relation matches subject sets like groups:admins
+	query := ketoClient.RelationQuery{
+		Namespace:  model.Namespace.ValueStringPointer(),
+		Object:     model.Object.ValueStringPointer(),
+		Relation:   model.Relation.ValueStringPointer(),
+		SubjectId:  model.SubjectId.ValueStringPointer(),
+		SubjectSet: subjectSetFromObject(model.SubjectSet),
+	}
+
+	relationships, err := getAllRelationships(ctx, d.provider, query, model.Snaptoken.ValueString())
+	if err != nil {
+		resp.Diagnostics.Append(ketoFrameworkDiagnostics(err, path.Empty())...)
+		return
+	}
+
+	relationTuples := make([]*ketoapi.RelationTuple, len(relationships))
+	tuples := make([]string, len(relationships))
+	for i, relationship := range relationships {
+		relationTuples[i] = ketoRelationshipToRelationTuple(relationship)
+		tuples[i] = relationTuples[i].String()
+	}
+
+	model.RelationTuple, diags = relationTupleListValue(ctx, relationTuples)
+	resp.Diagnostics.Append(diags...)
+	model.Tuples, diags = types.ListValueFrom(ctx, types.StringType, tuples)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	queryJson, err := json.Marshal(query)
+	if err != nil {
+		resp.Diagnostics.AddError(err.Error(), "")
+		return
+	}
+	model.Id = types.StringValue(fmt.Sprintf("%x", hash.UintString(string(queryJson))))
+	resp.Diagnostics.Append(resp.State.Set(ctx, model)...)
+}
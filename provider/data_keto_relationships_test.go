@@ -0,0 +1,135 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// nullObjectValue returns an object of objectType with the given attribute
+// values and null for all others.
+func nullObjectValue(objectType tftypes.Object, values map[string]tftypes.Value) tftypes.Value {
+	attributes := make(map[string]tftypes.Value, len(objectType.AttributeTypes))
+	for name, attributeType := range objectType.AttributeTypes {
+		if value, ok := values[name]; ok {
+			attributes[name] = value
+		} else {
+			attributes[name] = tftypes.NewValue(attributeType, nil)
+		}
+	}
+	return tftypes.NewValue(objectType, attributes)
+}
+
+func TestRelationshipsDataSourceRead(t *testing.T) {
+	var query map[string][]string
+	keto := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"relation_tuples":[` +
+			`{"namespace":"default","object":"app","relation":"write","subject_set":{"namespace":"groups","object":"admins","relation":""}},` +
+			`{"namespace":"default","object":"app","relation":"read","subject_id":"guest"}` +
+			`],"next_page_token":""}`))
+	}))
+	defer keto.Close()
+
+	ctx := context.Background()
+	server := providerserver.NewProtocol6(NewFrameworkProvider())()
+	schemaResp, err := server.GetProviderSchema(ctx, &tfprotov6.GetProviderSchemaRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	providerType := schemaResp.Provider.ValueType().(tftypes.Object)
+	providerConfig, err := tfprotov6.NewDynamicValue(providerType, nullObjectValue(providerType, map[string]tftypes.Value{
+		"url":         tftypes.NewValue(tftypes.String, keto.URL),
+		"read":        tftypes.NewValue(providerType.AttributeTypes["read"], []tftypes.Value{}),
+		"write":       tftypes.NewValue(providerType.AttributeTypes["write"], []tftypes.Value{}),
+		"opl":         tftypes.NewValue(providerType.AttributeTypes["opl"], []tftypes.Value{}),
+		"max_retries": tftypes.NewValue(tftypes.Number, 0),
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	configureResp, err := server.ConfigureProvider(ctx, &tfprotov6.ConfigureProviderRequest{Config: &providerConfig})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range configureResp.Diagnostics {
+		t.Fatalf("unexpected diagnostic: %s: %s", d.Summary, d.Detail)
+	}
+
+	dataType := schemaResp.DataSourceSchemas["oryketo_relationships"].ValueType().(tftypes.Object)
+	subjectSetType := dataType.AttributeTypes["subject_set"].(tftypes.Object)
+	dataConfig, err := tfprotov6.NewDynamicValue(dataType, nullObjectValue(dataType, map[string]tftypes.Value{
+		"namespace": tftypes.NewValue(tftypes.String, "default"),
+		"subject_set": nullObjectValue(subjectSetType, map[string]tftypes.Value{
+			"namespace": tftypes.NewValue(tftypes.String, "groups"),
+			"object":    tftypes.NewValue(tftypes.String, "admins"),
+		}),
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := server.ReadDataSource(ctx, &tfprotov6.ReadDataSourceRequest{
+		TypeName: "oryketo_relationships",
+		Config:   &dataConfig,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range resp.Diagnostics {
+		t.Fatalf("unexpected diagnostic: %s: %s", d.Summary, d.Detail)
+	}
+
+	if got := query["subject_set.namespace"]; len(got) != 1 || got[0] != "groups" {
+		t.Errorf("subject_set.namespace query = %v, want groups", got)
+	}
+	if got := query["namespace"]; len(got) != 1 || got[0] != "default" {
+		t.Errorf("namespace query = %v, want default", got)
+	}
+
+	state, err := resp.State.Unmarshal(dataType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var attributes map[string]tftypes.Value
+	if err := state.As(&attributes); err != nil {
+		t.Fatal(err)
+	}
+	var relationTuples []tftypes.Value
+	if err := attributes["relation_tuple"].As(&relationTuples); err != nil {
+		t.Fatal(err)
+	}
+	if len(relationTuples) != 2 {
+		t.Fatalf("got %d relation tuples, want 2", len(relationTuples))
+	}
+
+	tupleType := dataType.AttributeTypes["relation_tuple"].(tftypes.List).ElementType.(tftypes.Object)
+	wantTuples := []tftypes.Value{
+		nullObjectValue(tupleType, map[string]tftypes.Value{
+			"namespace": tftypes.NewValue(tftypes.String, "default"),
+			"object":    tftypes.NewValue(tftypes.String, "app"),
+			"relation":  tftypes.NewValue(tftypes.String, "write"),
+			"subject_set": nullObjectValue(tupleType.AttributeTypes["subject_set"].(tftypes.Object), map[string]tftypes.Value{
+				"namespace": tftypes.NewValue(tftypes.String, "groups"),
+				"object":    tftypes.NewValue(tftypes.String, "admins"),
+			}),
+		}),
+		nullObjectValue(tupleType, map[string]tftypes.Value{
+			"namespace":  tftypes.NewValue(tftypes.String, "default"),
+			"object":     tftypes.NewValue(tftypes.String, "app"),
+			"relation":   tftypes.NewValue(tftypes.String, "read"),
+			"subject_id": tftypes.NewValue(tftypes.String, "guest"),
+		}),
+	}
+	for i, want := range wantTuples {
+		if !relationTuples[i].Equal(want) {
+			t.Errorf("relation_tuple[%d] = %s, want %s", i, relationTuples[i], want)
+		}
+	}
+}
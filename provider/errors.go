@@ -0,0 +1,197 @@
+package provider
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/hashicorp/go-cty/cty"
+	fwdiag "github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	ketoClient "github.com/ory/keto-client-go"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// maxErrorBodyLength limits how much of a response body that is not a Keto
+// error is shown in diagnostics.
+const maxErrorBodyLength = 512
+
+// ketoError is a failed Keto or Ory API call. REST errors are decoded from
+// the herodot error body, gRPC errors from the call status and its details.
+type ketoError struct {
+	operation string
+	status    string
+	// badRequest is true when the request was rejected because of its
+	// content, for example a malformed tuple or an unknown namespace
+	badRequest bool
+	message    string
+	reason     string
+	requestId  string
+	debug      string
+	// body holds the raw response when it is not a herodot error, which is
+	// usually the case for errors returned by a proxy or gateway
+	body string
+}
+
+func (e *ketoError) Error() string {
+	message := e.message
+	if message == "" {
+		message = e.body
+	}
+	if message == "" {
+		return fmt.Sprintf("%s: %s", e.operation, e.status)
+	}
+	return fmt.Sprintf("%s: %s: %s", e.operation, e.status, message)
+}
+
+// newRestError converts an error returned by the generated REST client, or a
+// response with an unexpected status code, into a ketoError. Other errors,
+// such as network failures, are returned with the operation as context.
+func newRestError(operation string, resp *http.Response, err error) error {
+	var apiErr *ketoClient.GenericOpenAPIError
+	if resp == nil || (err != nil && !errors.As(err, &apiErr)) {
+		return fmt.Errorf("%s: %w", operation, err)
+	}
+
+	var body []byte
+	if apiErr != nil {
+		body = apiErr.Body()
+	} else if resp.Body != nil {
+		body, _ = io.ReadAll(resp.Body)
+	}
+	return newHerodotError(operation, resp.StatusCode, resp.Status, body)
+}
+
+// newHerodotError decodes the error format shared by Keto and the Ory APIs,
+// keeping the raw body when the response is in any other format.
+func newHerodotError(operation string, statusCode int, status string, body []byte) *ketoError {
+	ketoErr := &ketoError{
+		operation:  operation,
+		status:     status,
+		badRequest: statusCode == http.StatusBadRequest || statusCode == http.StatusNotFound || statusCode == http.StatusConflict,
+	}
+
+	var generic ketoClient.ErrorGeneric
+	if err := json.Unmarshal(body, &generic); err != nil || generic.Error.Message == "" {
+		ketoErr.body = strings.TrimSpace(string(body))
+		if len(ketoErr.body) > maxErrorBodyLength {
+			ketoErr.body = ketoErr.body[:maxErrorBodyLength] + "..."
+		}
+		return ketoErr
+	}
+
+	ketoErr.message = generic.Error.Message
+	ketoErr.reason = generic.Error.GetReason()
+	ketoErr.requestId = generic.Error.GetRequest()
+	ketoErr.debug = generic.Error.GetDebug()
+	return ketoErr
+}
+
+// newGrpcError converts the status of a failed gRPC call into a ketoError.
+func newGrpcError(operation string, err error) error {
+	st, ok := status.FromError(err)
+	if !ok {
+		return fmt.Errorf("%s: %w", operation, err)
+	}
+
+	ketoErr := &ketoError{
+		operation:  operation,
+		status:     st.Code().String(),
+		badRequest: st.Code() == codes.InvalidArgument || st.Code() == codes.NotFound || st.Code() == codes.AlreadyExists,
+		message:    st.Message(),
+	}
+	for _, detail := range st.Details() {
+		switch detail := detail.(type) {
+		case *errdetails.ErrorInfo:
+			ketoErr.reason = detail.GetReason()
+		case *errdetails.RequestInfo:
+			ketoErr.requestId = detail.GetRequestId()
+		case *errdetails.DebugInfo:
+			ketoErr.debug = detail.GetDetail()
+		}
+	}
+	return ketoErr
+}
+
+// ketoDiagnostics converts an error into diagnostics. Keto errors get a
+// summary naming the failed operation and a detail with everything Keto
+// returned, errors caused by the request content point at path.
+func ketoDiagnostics(err error, path cty.Path) diag.Diagnostics {
+	var ketoErr *ketoError
+	if !errors.As(err, &ketoErr) {
+		return diag.FromErr(err)
+	}
+
+	summary, detail := ketoErr.describe()
+	d := diag.Diagnostic{
+		Severity: diag.Error,
+		Summary:  summary,
+		Detail:   detail,
+	}
+	if ketoErr.isInputError() {
+		d.AttributePath = path
+	}
+	return diag.Diagnostics{d}
+}
+
+// ketoFrameworkDiagnostics is ketoDiagnostics for resources and data sources
+// implemented with terraform-plugin-framework, an empty path is ignored.
+func ketoFrameworkDiagnostics(err error, attributePath path.Path) fwdiag.Diagnostics {
+	var diags fwdiag.Diagnostics
+	var ketoErr *ketoError
+	if !errors.As(err, &ketoErr) {
+		diags.AddError(err.Error(), "")
+		return diags
+	}
+
+	summary, detail := ketoErr.describe()
+	if ketoErr.isInputError() && !attributePath.Equal(path.Empty()) {
+		diags.AddAttributeError(attributePath, summary, detail)
+	} else {
+		diags.AddError(summary, detail)
+	}
+	return diags
+}
+
+// isUndeclaredRelation reports whether Keto failed a check because relation
+// is not declared in the namespace OPL. Keto reports it as an internal error,
+// 500 or gRPC Unknown, so it is told apart from other failures by the
+// message only Keto itself returns.
+func (e *ketoError) isUndeclaredRelation(relation string) bool {
+	return e.body == "" && e.message == fmt.Sprintf("relation %q not found", relation)
+}
+
+// isInputError reports whether Keto rejected the request because of its
+// content, so the diagnostic can point at the attribute holding it.
+func (e *ketoError) isInputError() bool {
+	return e.badRequest && e.body == ""
+}
+
+// describe returns the summary and detail of the diagnostic for the error.
+func (e *ketoError) describe() (string, string) {
+	var detail []string
+	if e.message != "" {
+		detail = append(detail, e.message)
+	}
+	if e.reason != "" {
+		detail = append(detail, "Reason: "+e.reason)
+	}
+	if e.debug != "" {
+		detail = append(detail, "Debug: "+e.debug)
+	}
+	if e.requestId != "" {
+		detail = append(detail, "Request ID: "+e.requestId)
+	}
+	if e.body != "" {
+		detail = append(detail, "The response is not a Keto error and may come from a proxy or gateway in front of Keto: "+e.body)
+	} else if e.message == "" {
+		detail = append(detail, "The response has no body.")
+	}
+	return fmt.Sprintf("Failed to %s: %s", e.operation, e.status), strings.Join(detail, "\n")
+}
@@ -0,0 +1,192 @@
+package provider
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/go-cty/cty"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestNewHerodotError(t *testing.T) {
+	tests := []struct {
+		name        string
+		statusCode  int
+		status      string
+		body        string
+		wantSummary string
+		wantDetail  string
+		wantInput   bool
+	}{
+		{
+			name:        "keto bad request",
+			statusCode:  http.StatusBadRequest,
+			status:      "400 Bad Request",
+			body:        `{"error":{"code":400,"status":"Bad Request","message":"unknown namespace","reason":"namespace foo not found","request":"req-1","debug":"trace"}}`,
+			wantSummary: "Failed to create relationship: 400 Bad Request",
+			wantDetail:  "unknown namespace\nReason: namespace foo not found\nDebug: trace\nRequest ID: req-1",
+			wantInput:   true,
+		},
+		{
+			name:        "keto conflict",
+			statusCode:  http.StatusConflict,
+			status:      "409 Conflict",
+			body:        `{"error":{"code":409,"status":"Conflict","message":"relationship already exists"}}`,
+			wantSummary: "Failed to create relationship: 409 Conflict",
+			wantDetail:  "relationship already exists",
+			wantInput:   true,
+		},
+		{
+			name:        "keto server error",
+			statusCode:  http.StatusInternalServerError,
+			status:      "500 Internal Server Error",
+			body:        `{"error":{"code":500,"status":"Internal Server Error","message":"database unavailable"}}`,
+			wantSummary: "Failed to create relationship: 500 Internal Server Error",
+			wantDetail:  "database unavailable",
+		},
+		{
+			name:        "gateway html",
+			statusCode:  http.StatusBadRequest,
+			status:      "400 Bad Request",
+			body:        "<html>bad request</html>\n",
+			wantSummary: "Failed to create relationship: 400 Bad Request",
+			wantDetail:  "The response is not a Keto error and may come from a proxy or gateway in front of Keto: <html>bad request</html>",
+		},
+		{
+			name:        "empty body",
+			statusCode:  http.StatusBadGateway,
+			status:      "502 Bad Gateway",
+			wantSummary: "Failed to create relationship: 502 Bad Gateway",
+			wantDetail:  "The response has no body.",
+		},
+		{
+			name:        "long body",
+			statusCode:  http.StatusBadGateway,
+			status:      "502 Bad Gateway",
+			body:        strings.Repeat("x", maxErrorBodyLength+10),
+			wantSummary: "Failed to create relationship: 502 Bad Gateway",
+			wantDetail:  "The response is not a Keto error and may come from a proxy or gateway in front of Keto: " + strings.Repeat("x", maxErrorBodyLength) + "...",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ketoErr := newHerodotError("create relationship", tt.statusCode, tt.status, []byte(tt.body))
+			summary, detail := ketoErr.describe()
+			if summary != tt.wantSummary {
+				t.Errorf("got summary %q, want %q", summary, tt.wantSummary)
+			}
+			if detail != tt.wantDetail {
+				t.Errorf("got detail %q, want %q", detail, tt.wantDetail)
+			}
+			if got := ketoErr.isInputError(); got != tt.wantInput {
+				t.Errorf("got input error %v, want %v", got, tt.wantInput)
+			}
+		})
+	}
+}
+
+func TestNewRestError(t *testing.T) {
+	networkErr := errors.New("connection refused")
+	if err := newRestError("check permission", nil, networkErr); !errors.Is(err, networkErr) || err.Error() != "check permission: connection refused" {
+		t.Fatalf("got %v, want the network error with the operation as context", err)
+	}
+
+	// responses with an unexpected status code and no client error
+	resp := &http.Response{
+		StatusCode: http.StatusCreated,
+		Status:     "201 Created",
+		Body:       io.NopCloser(strings.NewReader(`{"error":{"code":201,"message":"unexpected"}}`)),
+	}
+	var ketoErr *ketoError
+	if err := newRestError("check permission", resp, nil); !errors.As(err, &ketoErr) || ketoErr.message != "unexpected" {
+		t.Fatalf("got %v, want the decoded response body", err)
+	}
+}
+
+func TestNewGrpcError(t *testing.T) {
+	st, err := status.New(codes.InvalidArgument, "unknown namespace").WithDetails(
+		&errdetails.ErrorInfo{Reason: "namespace foo not found"},
+		&errdetails.RequestInfo{RequestId: "req-1"},
+		&errdetails.DebugInfo{Detail: "trace"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		err         error
+		wantSummary string
+		wantDetail  string
+		wantInput   bool
+	}{
+		{
+			name:        "invalid argument with details",
+			err:         st.Err(),
+			wantSummary: "Failed to create relationship: InvalidArgument",
+			wantDetail:  "unknown namespace\nReason: namespace foo not found\nDebug: trace\nRequest ID: req-1",
+			wantInput:   true,
+		},
+		{
+			name:        "already exists",
+			err:         status.Error(codes.AlreadyExists, "relationship already exists"),
+			wantSummary: "Failed to create relationship: AlreadyExists",
+			wantDetail:  "relationship already exists",
+			wantInput:   true,
+		},
+		{
+			name:        "unavailable",
+			err:         status.Error(codes.Unavailable, "connection refused"),
+			wantSummary: "Failed to create relationship: Unavailable",
+			wantDetail:  "connection refused",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ketoErr *ketoError
+			if !errors.As(newGrpcError("create relationship", tt.err), &ketoErr) {
+				t.Fatalf("got %T, want *ketoError", ketoErr)
+			}
+			summary, detail := ketoErr.describe()
+			if summary != tt.wantSummary {
+				t.Errorf("got summary %q, want %q", summary, tt.wantSummary)
+			}
+			if detail != tt.wantDetail {
+				t.Errorf("got detail %q, want %q", detail, tt.wantDetail)
+			}
+			if got := ketoErr.isInputError(); got != tt.wantInput {
+				t.Errorf("got input error %v, want %v", got, tt.wantInput)
+			}
+		})
+	}
+}
+
+func TestKetoDiagnosticsAttributePath(t *testing.T) {
+	path := cty.GetAttrPath("tuples")
+	tests := []struct {
+		name     string
+		err      error
+		wantPath bool
+	}{
+		{"keto input error", newHerodotError("patch relationships", http.StatusBadRequest, "400 Bad Request", []byte(`{"error":{"code":400,"message":"malformed tuple"}}`)), true},
+		{"gateway input error", newHerodotError("patch relationships", http.StatusBadRequest, "400 Bad Request", []byte("bad request")), false},
+		{"server error", newHerodotError("patch relationships", http.StatusInternalServerError, "500 Internal Server Error", nil), false},
+		{"other error", errors.New("connection refused"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diags := ketoDiagnostics(tt.err, path)
+			if len(diags) != 1 {
+				t.Fatalf("got diagnostics %v, want one", diags)
+			}
+			if got := diags[0].AttributePath.Equals(path); got != tt.wantPath {
+				t.Fatalf("got attribute path %v, want path %v", diags[0].AttributePath, tt.wantPath)
+			}
+		})
+	}
+}
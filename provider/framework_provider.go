@@ -0,0 +1,283 @@
+package provider
+
+import (
+	"context"
+	"os"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	fwprovider "github.com/hashicorp/terraform-plugin-framework/provider"
+	pschema "github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-mux/tf5to6server"
+)
+
+// ProviderServers returns the servers muxed into the oryketo provider, the
+// SDKv2 provider is upgraded to protocol 6 to be served next to the framework
+// provider.
+func ProviderServers(ctx context.Context) ([]func() tfprotov6.ProviderServer, error) {
+	sdkServer, err := tf5to6server.UpgradeServer(ctx, Provider(ctx).GRPCProvider)
+	if err != nil {
+		return nil, err
+	}
+	return []func() tfprotov6.ProviderServer{
+		func() tfprotov6.ProviderServer {
+			return sdkServer
+		},
+		providerserver.NewProtocol6(NewFrameworkProvider()),
+	}, nil
+}
+
+// frameworkProvider serves the resources and data sources implemented with
+// terraform-plugin-framework. Its schema must stay identical to the SDKv2
+// provider schema, which also validates the provider configuration.
+type frameworkProvider struct{}
+
+var _ fwprovider.ProviderWithFunctions = &frameworkProvider{}
+
+func NewFrameworkProvider() fwprovider.Provider {
+	return &frameworkProvider{}
+}
+
+type frameworkProviderModel struct {
+	Protocol             types.String          `tfsdk:"protocol"`
+	Url                  types.String          `tfsdk:"url"`
+	ProjectSlug          types.String          `tfsdk:"project_slug"`
+	SdkUrl               types.String          `tfsdk:"sdk_url"`
+	ApiKey               types.String          `tfsdk:"api_key"`
+	ValidateNamespaces   types.Bool            `tfsdk:"validate_namespaces"`
+	RequestTimeout       types.String          `tfsdk:"request_timeout"`
+	MaxRetries           types.Int64           `tfsdk:"max_retries"`
+	MinBackoff           types.String          `tfsdk:"min_backoff"`
+	MaxBackoff           types.String          `tfsdk:"max_backoff"`
+	RetryableStatusCodes types.Set             `tfsdk:"retryable_status_codes"`
+	WorkspaceApiKey      types.String          `tfsdk:"workspace_api_key"`
+	ConsoleUrl           types.String          `tfsdk:"console_url"`
+	Read                 []apiClientBlockModel `tfsdk:"read"`
+	Write                []apiClientBlockModel `tfsdk:"write"`
+	Opl                  []apiClientBlockModel `tfsdk:"opl"`
+}
+
+type apiClientBlockModel struct {
+	Url                types.String `tfsdk:"url"`
+	Headers            types.Map    `tfsdk:"headers"`
+	CaCert             types.String `tfsdk:"ca_cert"`
+	ClientCert         types.String `tfsdk:"client_cert"`
+	ClientKey          types.String `tfsdk:"client_key"`
+	InsecureSkipVerify types.Bool   `tfsdk:"insecure_skip_verify"`
+}
+
+func (p *frameworkProvider) Metadata(ctx context.Context, req fwprovider.MetadataRequest, resp *fwprovider.MetadataResponse) {
+	resp.TypeName = "oryketo"
+}
+
+func (p *frameworkProvider) Schema(ctx context.Context, req fwprovider.SchemaRequest, resp *fwprovider.SchemaResponse) {
+	resp.Schema = pschema.Schema{
+		Attributes: map[string]pschema.Attribute{
+			"protocol": pschema.StringAttribute{
+				Optional: true,
+			},
+			"url": pschema.StringAttribute{
+				Optional: true,
+			},
+			"project_slug": pschema.StringAttribute{
+				Optional: true,
+			},
+			"sdk_url": pschema.StringAttribute{
+				Optional: true,
+			},
+			"api_key": pschema.StringAttribute{
+				Optional:  true,
+				Sensitive: true,
+			},
+			"validate_namespaces": pschema.BoolAttribute{
+				Optional: true,
+			},
+			"request_timeout": pschema.StringAttribute{
+				Optional: true,
+			},
+			"max_retries": pschema.Int64Attribute{
+				Optional: true,
+			},
+			"min_backoff": pschema.StringAttribute{
+				Optional: true,
+			},
+			"max_backoff": pschema.StringAttribute{
+				Optional: true,
+			},
+			"retryable_status_codes": pschema.SetAttribute{
+				Optional:    true,
+				ElementType: types.Int64Type,
+			},
+			"workspace_api_key": pschema.StringAttribute{
+				Optional:  true,
+				Sensitive: true,
+			},
+			"console_url": pschema.StringAttribute{
+				Optional: true,
+			},
+		},
+		Blocks: map[string]pschema.Block{
+			"read":  apiClientBlockFrameworkSchema(),
+			"write": apiClientBlockFrameworkSchema(),
+			"opl":   apiClientBlockFrameworkSchema(),
+		},
+	}
+}
+
+// apiClientBlockFrameworkSchema mirrors apiClientBlockSchema.
+func apiClientBlockFrameworkSchema() pschema.Block {
+	return pschema.ListNestedBlock{
+		Validators: []validator.List{
+			listvalidator.SizeAtMost(1),
+		},
+		NestedObject: pschema.NestedBlockObject{
+			Attributes: map[string]pschema.Attribute{
+				"url": pschema.StringAttribute{
+					Optional: true,
+				},
+				"headers": pschema.MapAttribute{
+					Optional:    true,
+					Sensitive:   true,
+					ElementType: types.StringType,
+				},
+				"ca_cert": pschema.StringAttribute{
+					Optional: true,
+				},
+				"client_cert": pschema.StringAttribute{
+					Optional: true,
+				},
+				"client_key": pschema.StringAttribute{
+					Optional:  true,
+					Sensitive: true,
+				},
+				"insecure_skip_verify": pschema.BoolAttribute{
+					Optional: true,
+				},
+			},
+		},
+	}
+}
+
+func (p *frameworkProvider) Configure(ctx context.Context, req fwprovider.ConfigureRequest, resp *fwprovider.ConfigureResponse) {
+	var model frameworkProviderModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &model)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	settings, diags := model.settings(ctx)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	config, err := newProviderConfig(settings)
+	if err != nil {
+		resp.Diagnostics.AddError(err.Error(), "")
+		return
+	}
+	resp.DataSourceData = config
+	resp.ResourceData = config
+}
+
+// settings applies the defaults and environment variables the SDKv2 schema
+// declares, the framework schema can't hold them without changing the
+// provider schema. Both use the same constants so the providers are
+// configured alike.
+func (m frameworkProviderModel) settings(ctx context.Context) (providerSettings, diag.Diagnostics) {
+	settings := providerSettings{
+		protocol:           stringOrDefault(m.Protocol, defaultProtocol),
+		url:                m.Url.ValueString(),
+		projectSlug:        m.ProjectSlug.ValueString(),
+		sdkUrl:             m.SdkUrl.ValueString(),
+		apiKey:             stringOrDefault(m.ApiKey, os.Getenv(apiKeyEnv)),
+		validateNamespaces: defaultValidateNamespaces,
+		requestTimeout:     m.RequestTimeout.ValueString(),
+		maxRetries:         defaultMaxRetries,
+		minBackoff:         stringOrDefault(m.MinBackoff, defaultMinBackoff),
+		maxBackoff:         stringOrDefault(m.MaxBackoff, defaultMaxBackoff),
+		workspaceApiKey:    stringOrDefault(m.WorkspaceApiKey, os.Getenv(workspaceApiKeyEnv)),
+		consoleUrl:         stringOrDefault(m.ConsoleUrl, os.Getenv(consoleUrlEnv)),
+	}
+	if settings.consoleUrl == "" {
+		settings.consoleUrl = defaultOryConsoleUrl
+	}
+	if !m.ValidateNamespaces.IsNull() && !m.ValidateNamespaces.IsUnknown() {
+		settings.validateNamespaces = m.ValidateNamespaces.ValueBool()
+	}
+	if !m.MaxRetries.IsNull() && !m.MaxRetries.IsUnknown() {
+		settings.maxRetries = int(m.MaxRetries.ValueInt64())
+	}
+
+	var diags diag.Diagnostics
+	var codes []int64
+	diags.Append(m.RetryableStatusCodes.ElementsAs(ctx, &codes, false)...)
+	for _, code := range codes {
+		settings.retryableStatusCodes = append(settings.retryableStatusCodes, int(code))
+	}
+
+	var blockDiags diag.Diagnostics
+	settings.read, blockDiags = apiClientBlockSettings(ctx, m.Read, readUrlEnv)
+	diags.Append(blockDiags...)
+	settings.write, blockDiags = apiClientBlockSettings(ctx, m.Write, writeUrlEnv)
+	diags.Append(blockDiags...)
+	settings.opl, blockDiags = apiClientBlockSettings(ctx, m.Opl, oplUrlEnv)
+	diags.Append(blockDiags...)
+	return settings, diags
+}
+
+func apiClientBlockSettings(ctx context.Context, blocks []apiClientBlockModel, urlEnv string) (*apiClientSettings, diag.Diagnostics) {
+	if len(blocks) == 0 {
+		return nil, nil
+	}
+	block := blocks[0]
+	settings := &apiClientSettings{
+		url:                stringOrDefault(block.Url, os.Getenv(urlEnv)),
+		headers:            make(map[string]string),
+		caCert:             block.CaCert.ValueString(),
+		clientCert:         block.ClientCert.ValueString(),
+		clientKey:          block.ClientKey.ValueString(),
+		insecureSkipVerify: block.InsecureSkipVerify.ValueBool(),
+	}
+	if block.Headers.IsNull() {
+		return settings, nil
+	}
+	diags := block.Headers.ElementsAs(ctx, &settings.headers, false)
+	return settings, diags
+}
+
+func stringOrDefault(value types.String, defaultValue string) string {
+	if value.IsNull() || value.IsUnknown() {
+		return defaultValue
+	}
+	return value.ValueString()
+}
+
+func (p *frameworkProvider) Resources(ctx context.Context) []func() resource.Resource {
+	return []func() resource.Resource{
+		newRelationshipResource,
+	}
+}
+
+func (p *frameworkProvider) DataSources(ctx context.Context) []func() datasource.DataSource {
+	return []func() datasource.DataSource{
+		newRelationshipParseDataSource,
+		newPermissionCheckDataSource,
+		newRelationshipsDataSource,
+	}
+}
+
+func (p *frameworkProvider) Functions(ctx context.Context) []func() function.Function {
+	return []func() function.Function{
+		newParseTupleFunction,
+		newParseTuplesFunction,
+		newFormatTupleFunction,
+	}
+}
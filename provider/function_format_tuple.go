@@ -0,0 +1,75 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ function.Function = &formatTupleFunction{}
+
+type formatTupleFunction struct{}
+
+func newFormatTupleFunction() function.Function {
+	return &formatTupleFunction{}
+}
+
+func (f *formatTupleFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "format_tuple"
+}
+
+func (f *formatTupleFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:     "Format a relation tuple",
+		Description: "Formats an object with the attributes of oryketo_relationship, such as the result of parse_tuple, as a relation tuple in Google Zanzibar text notation.",
+		Parameters: []function.Parameter{
+			function.ObjectParameter{
+				Name:           "tuple",
+				Description:    "Relation tuple object, exactly one of subject_id and subject_set must not be null.",
+				AttributeTypes: tupleAttrTypes,
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *formatTupleFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var tuple tupleModel
+	resp.Error = req.Arguments.Get(ctx, &tuple)
+	if resp.Error != nil {
+		return
+	}
+
+	if tuple.SubjectId.IsNull() == tuple.SubjectSet.IsNull() {
+		resp.Error = function.NewArgumentFuncError(0, "exactly one of subject_id and subject_set must be set")
+		return
+	}
+	if name := missingTupleAttribute(tuple); name != "" {
+		resp.Error = function.NewArgumentFuncError(0, name+" must be set")
+		return
+	}
+	resp.Error = resp.Result.Set(ctx, ketoRelationshipToRelationTuple(tuple.relationship()).String())
+}
+
+// missingTupleAttribute returns the name of the first attribute that is null
+// or empty and would be formatted as a malformed tuple, empty when there is
+// none.
+func missingTupleAttribute(tuple tupleModel) string {
+	values := []types.String{tuple.Namespace, tuple.Object, tuple.Relation}
+	names := []string{"namespace", "object", "relation"}
+	if tuple.SubjectSet.IsNull() {
+		values = append(values, tuple.SubjectId)
+		names = append(names, "subject_id")
+	} else {
+		attrs := tuple.SubjectSet.Attributes()
+		values = append(values, attrs["namespace"].(types.String), attrs["object"].(types.String))
+		names = append(names, "subject_set.namespace", "subject_set.object")
+	}
+	for i, value := range values {
+		if value.ValueString() == "" {
+			return names[i]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,122 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestFormatTupleFunctionRun(t *testing.T) {
+	subjectSet := func(namespace, object, relation types.String) types.Object {
+		return types.ObjectValueMust(subjectSetAttrTypes, map[string]attr.Value{
+			"namespace": namespace,
+			"object":    object,
+			"relation":  relation,
+		})
+	}
+	tests := []struct {
+		name       string
+		subjectId  types.String
+		subjectSet types.Object
+		namespace  types.String
+		want       string
+		wantErr    string
+	}{
+		{
+			name:       "subject id",
+			subjectId:  types.StringValue("guest"),
+			subjectSet: types.ObjectNull(subjectSetAttrTypes),
+			namespace:  types.StringValue("default"),
+			want:       "default:app#read@guest",
+		},
+		{
+			name:       "subject set",
+			subjectId:  types.StringNull(),
+			subjectSet: subjectSet(types.StringValue("groups"), types.StringValue("admins"), types.StringValue("member")),
+			namespace:  types.StringValue("default"),
+			want:       "default:app#read@groups:admins#member",
+		},
+		{
+			name:       "subject set without relation",
+			subjectId:  types.StringNull(),
+			subjectSet: subjectSet(types.StringValue("groups"), types.StringValue("admins"), types.StringNull()),
+			namespace:  types.StringValue("default"),
+			want:       "default:app#read@groups:admins",
+		},
+		{
+			name:       "no subject",
+			subjectId:  types.StringNull(),
+			subjectSet: types.ObjectNull(subjectSetAttrTypes),
+			namespace:  types.StringValue("default"),
+			wantErr:    "exactly one of subject_id and subject_set must be set",
+		},
+		{
+			name:       "both subjects",
+			subjectId:  types.StringValue("guest"),
+			subjectSet: subjectSet(types.StringValue("groups"), types.StringValue("admins"), types.StringNull()),
+			namespace:  types.StringValue("default"),
+			wantErr:    "exactly one of subject_id and subject_set must be set",
+		},
+		{
+			name:       "null subject set namespace",
+			subjectId:  types.StringNull(),
+			subjectSet: subjectSet(types.StringNull(), types.StringValue("admins"), types.StringNull()),
+			namespace:  types.StringValue("default"),
+			wantErr:    "subject_set.namespace must be set",
+		},
+		{
+			name:       "null subject set object",
+			subjectId:  types.StringNull(),
+			subjectSet: subjectSet(types.StringValue("groups"), types.StringNull(), types.StringNull()),
+			namespace:  types.StringValue("default"),
+			wantErr:    "subject_set.object must be set",
+		},
+		{
+			name:       "empty subject id",
+			subjectId:  types.StringValue(""),
+			subjectSet: types.ObjectNull(subjectSetAttrTypes),
+			namespace:  types.StringValue("default"),
+			wantErr:    "subject_id must be set",
+		},
+		{
+			name:       "null namespace",
+			subjectId:  types.StringValue("guest"),
+			subjectSet: types.ObjectNull(subjectSetAttrTypes),
+			namespace:  types.StringNull(),
+			wantErr:    "namespace must be set",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tuple := types.ObjectValueMust(tupleAttrTypes, map[string]attr.Value{
+				"namespace":   tt.namespace,
+				"object":      types.StringValue("app"),
+				"relation":    types.StringValue("read"),
+				"subject_id":  tt.subjectId,
+				"subject_set": tt.subjectSet,
+			})
+			req := function.RunRequest{Arguments: function.NewArgumentsData([]attr.Value{tuple})}
+			resp := &function.RunResponse{Result: function.NewResultData(types.StringUnknown())}
+			newFormatTupleFunction().Run(context.Background(), req, resp)
+
+			if tt.wantErr != "" {
+				if resp.Error == nil || resp.Error.Text != tt.wantErr {
+					t.Fatalf("got error %v, want %q", resp.Error, tt.wantErr)
+				}
+				if resp.Error.FunctionArgument == nil || *resp.Error.FunctionArgument != 0 {
+					t.Fatalf("got error argument %v, want 0", resp.Error.FunctionArgument)
+				}
+				return
+			}
+			if resp.Error != nil {
+				t.Fatalf("got error %v, want none", resp.Error)
+			}
+			if got := resp.Result.Value().(types.String).ValueString(); got != tt.want {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
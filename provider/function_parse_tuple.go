@@ -0,0 +1,51 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+var _ function.Function = &parseTupleFunction{}
+
+type parseTupleFunction struct{}
+
+func newParseTupleFunction() function.Function {
+	return &parseTupleFunction{}
+}
+
+func (f *parseTupleFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "parse_tuple"
+}
+
+func (f *parseTupleFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:     "Parse a relation tuple",
+		Description: "Parses a relation tuple in Google Zanzibar text notation into an object with the attributes of oryketo_relationship.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:        "tuple",
+				Description: "Relation tuple in text notation, such as default:app#read@guest.",
+			},
+		},
+		Return: function.ObjectReturn{
+			AttributeTypes: tupleAttrTypes,
+		},
+	}
+}
+
+func (f *parseTupleFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var tuple string
+	resp.Error = req.Arguments.Get(ctx, &tuple)
+	if resp.Error != nil {
+		return
+	}
+
+	rt, err := stringToRelationTuple(tuple)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("%q is not a valid relation tuple: %s", tuple, err))
+		return
+	}
+	resp.Error = resp.Result.Set(ctx, tupleModelFrom(ketoRelationTupleToRelationship(rt)))
+}
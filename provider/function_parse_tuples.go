@@ -0,0 +1,57 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ function.Function = &parseTuplesFunction{}
+
+type parseTuplesFunction struct{}
+
+func newParseTuplesFunction() function.Function {
+	return &parseTuplesFunction{}
+}
+
+func (f *parseTuplesFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "parse_tuples"
+}
+
+func (f *parseTuplesFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:     "Parse relation tuples",
+		Description: "Parses relation tuples in Google Zanzibar text notation, one per line, into a list of objects with the attributes of oryketo_relationship. Empty lines are ignored.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:        "tuples",
+				Description: "Relation tuples in text notation, one per line.",
+			},
+		},
+		Return: function.ListReturn{
+			ElementType: types.ObjectType{AttrTypes: tupleAttrTypes},
+		},
+	}
+}
+
+func (f *parseTuplesFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var tuples string
+	resp.Error = req.Arguments.Get(ctx, &tuples)
+	if resp.Error != nil {
+		return
+	}
+
+	rts, err := stringToRelationTuples(tuples)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("invalid relation tuples: %s", err))
+		return
+	}
+
+	result := make([]tupleModel, len(rts))
+	for i, rt := range rts {
+		result[i] = tupleModelFrom(ketoRelationTupleToRelationship(rt))
+	}
+	resp.Error = resp.Result.Set(ctx, result)
+}
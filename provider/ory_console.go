@@ -0,0 +1,130 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const (
+	defaultOryConsoleUrl       = "https://api.console.ory.sh"
+	oryNamespacesConfigPath    = "/services/permission/config/namespaces"
+	oryNamespacesBase64Prefix  = "base64://"
+	oryConsoleProjectUrlFormat = "%s/projects/%s"
+)
+
+// errOryConsoleNotConfigured is returned when a resource needs the Ory Network
+// console API but the provider has no workspace API key.
+var errOryConsoleNotConfigured = errors.New("workspace_api_key must be set to manage Ory Network project configuration")
+
+// oryConsoleClient manages Ory Network project configuration, which is where
+// Ory Permission Language namespaces are stored for managed Keto.
+type oryConsoleClient struct {
+	url        string
+	apiKey     string
+	httpClient *http.Client
+}
+
+type oryProject struct {
+	Services struct {
+		Permission struct {
+			Config struct {
+				Namespaces json.RawMessage `json:"namespaces"`
+			} `json:"config"`
+		} `json:"permission"`
+	} `json:"services"`
+}
+
+type oryNamespacesConfig struct {
+	Location string `json:"location"`
+}
+
+type oryJsonPatch struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value"`
+}
+
+// getNamespacesOpl returns the OPL document of the project, found is false
+// when namespaces are not configured through OPL.
+func (c *oryConsoleClient) getNamespacesOpl(ctx context.Context, projectId string) (string, bool, error) {
+	var project oryProject
+	if err := c.do(ctx, "get project configuration", http.MethodGet, projectId, nil, &project); err != nil {
+		return "", false, err
+	}
+
+	var namespaces oryNamespacesConfig
+	raw := project.Services.Permission.Config.Namespaces
+	if len(raw) == 0 || json.Unmarshal(raw, &namespaces) != nil {
+		// legacy namespaces are configured as a list, which holds no OPL
+		return "", false, nil
+	}
+	if !strings.HasPrefix(namespaces.Location, oryNamespacesBase64Prefix) {
+		return "", false, nil
+	}
+
+	opl, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(namespaces.Location, oryNamespacesBase64Prefix))
+	if err != nil {
+		return "", false, fmt.Errorf("decode namespaces location: %v", err)
+	}
+	return string(opl), true, nil
+}
+
+func (c *oryConsoleClient) setNamespacesOpl(ctx context.Context, projectId string, opl string) error {
+	patch := []oryJsonPatch{
+		{
+			Op:   "replace",
+			Path: oryNamespacesConfigPath,
+			Value: oryNamespacesConfig{
+				Location: oryNamespacesBase64Prefix + base64.StdEncoding.EncodeToString([]byte(opl)),
+			},
+		},
+	}
+	return c.do(ctx, "update project namespaces", http.MethodPatch, projectId, patch, nil)
+}
+
+func (c *oryConsoleClient) do(ctx context.Context, operation string, method string, projectId string, body interface{}, out interface{}) error {
+	var reqBody io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, fmt.Sprintf(oryConsoleProjectUrlFormat, c.url, projectId), reqBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "terraform/ory-keto-provider")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 300 {
+		return newHerodotError(operation, resp.StatusCode, resp.Status, respBody)
+	}
+	if out != nil {
+		return json.Unmarshal(respBody, out)
+	}
+	return nil
+}
@@ -2,118 +2,518 @@ package provider
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net/http"
 	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/hashicorp/go-cleanhttp"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	keto "github.com/ory/keto-client-go"
 )
 
+const (
+	oryNetworkProjectUrlFormat = "https://%s.projects.oryapis.com"
+	readUrlEnv                 = "ORY_KETO_READ_URL"
+	writeUrlEnv                = "ORY_KETO_WRITE_URL"
+	oplUrlEnv                  = "ORY_KETO_OPL_URL"
+	urlEnv                     = "ORY_KETO_URL"
+	projectSlugEnv             = "ORY_PROJECT_SLUG"
+	sdkUrlEnv                  = "ORY_SDK_URL"
+	apiKeyEnv                  = "ORY_API_KEY"
+	workspaceApiKeyEnv         = "ORY_WORKSPACE_API_KEY"
+	consoleUrlEnv              = "ORY_CONSOLE_URL"
+)
+
+// Defaults of the provider settings, shared by the SDKv2 schema and the
+// framework provider, which applies them in settings.
+const (
+	defaultProtocol           = protocolRest
+	defaultValidateNamespaces = false
+	defaultMaxRetries         = 3
+	defaultMinBackoff         = "1s"
+	defaultMaxBackoff         = "30s"
+)
+
 type providerConfig struct {
-	readApiClient  *keto.APIClient
-	writeApiClient *keto.APIClient
+	backend            ketoBackend
+	console            *oryConsoleClient
+	validateNamespaces bool
+
+	namespacesOnce sync.Once
+	namespaces     map[string]bool
+	namespacesErr  error
+}
+
+// apiClientConfig holds the settings used to build a single Keto API client.
+type apiClientConfig struct {
+	url                string
+	headers            map[string]string
+	timeout            time.Duration
+	caCert             string
+	clientCert         string
+	clientKey          string
+	insecureSkipVerify bool
+	retry              retryConfig
 }
 
 func Provider(ctx context.Context) *schema.Provider {
 	provider := &schema.Provider{
 		Schema: map[string]*schema.Schema{
+			"protocol": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      defaultProtocol,
+				ValidateFunc: validation.StringInSlice([]string{protocolRest, protocolGrpc}, false),
+			},
+			// the environment variables of url, project_slug and sdk_url are
+			// applied by newProviderConfig, so one set in the environment
+			// doesn't conflict with another one set in the configuration
+			"url": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"project_slug": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"sdk_url": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"api_key": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Sensitive:   true,
+				DefaultFunc: schema.EnvDefaultFunc(apiKeyEnv, nil),
+			},
+			"validate_namespaces": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  defaultValidateNamespaces,
+			},
+			"request_timeout": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateDuration,
+			},
+			"max_retries": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      defaultMaxRetries,
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"min_backoff": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      defaultMinBackoff,
+				ValidateFunc: validateDuration,
+			},
+			"max_backoff": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      defaultMaxBackoff,
+				ValidateFunc: validateDuration,
+			},
+			"retryable_status_codes": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type:         schema.TypeInt,
+					ValidateFunc: validation.IntBetween(100, 599),
+				},
+			},
+			"workspace_api_key": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Sensitive:   true,
+				DefaultFunc: schema.EnvDefaultFunc(workspaceApiKeyEnv, nil),
+			},
+			"console_url": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc(consoleUrlEnv, defaultOryConsoleUrl),
+			},
 			"read": {
 				Type:     schema.TypeList,
-				Required: true,
+				Optional: true,
 				MaxItems: 1,
-				Elem: &schema.Resource{
-					Schema: map[string]*schema.Schema{
-						"url": {
-							Type:        schema.TypeString,
-							Required:    true,
-							DefaultFunc: schema.EnvDefaultFunc("ORY_KETO_READ_URL", nil),
-						},
-						"headers": {
-							Type:     schema.TypeMap,
-							Optional: true,
-						},
-					},
-				},
+				Elem:     apiClientBlockSchema(readUrlEnv),
 			},
 			"write": {
 				Type:     schema.TypeList,
-				Required: true,
+				Optional: true,
 				MaxItems: 1,
-				Elem: &schema.Resource{
-					Schema: map[string]*schema.Schema{
-						"url": {
-							Type:        schema.TypeString,
-							Required:    true,
-							DefaultFunc: schema.EnvDefaultFunc("ORY_KETO_WRITE_URL", nil),
-						},
-						"headers": {
-							Type:     schema.TypeMap,
-							Optional: true,
-						},
-					},
-				},
+				Elem:     apiClientBlockSchema(writeUrlEnv),
+			},
+			"opl": {
+				Type:     schema.TypeList,
+				Optional: true,
+				MaxItems: 1,
+				Elem:     apiClientBlockSchema(oplUrlEnv),
 			},
 		},
 		ResourcesMap: map[string]*schema.Resource{
-			"oryketo_relationship": resourceKetoRelationship(),
+			"oryketo_relationships":    resourceKetoRelationships(),
+			"oryketo_namespace_config": resourceKetoNamespaceConfig(),
 		},
 		DataSourcesMap: map[string]*schema.Resource{
-			"oryketo_relationship_parse": dataKetoRelationshipParse(),
-			"oryketo_permission_check":   dataKetoPermissionCheck(),
+			"oryketo_permission_checks": dataKetoPermissionChecks(),
+			"oryketo_permission_expand": dataKetoPermissionExpand(),
+			"oryketo_opl_check":         dataKetoOplCheck(),
+			"oryketo_namespaces":        dataKetoNamespaces(),
 		},
 		ConfigureContextFunc: configureProvider,
 	}
 	return provider
 }
 
+// getNamespaces returns the set of namespaces known to Keto, the list is read
+// once and shared by all resources during a run.
+func (p *providerConfig) getNamespaces(ctx context.Context) (map[string]bool, error) {
+	p.namespacesOnce.Do(func() {
+		namespaces, err := p.backend.listNamespaces(ctx)
+		if err != nil {
+			p.namespacesErr = err
+			return
+		}
+		p.namespaces = make(map[string]bool, len(namespaces))
+		for _, namespace := range namespaces {
+			p.namespaces[namespace] = true
+		}
+	})
+	return p.namespaces, p.namespacesErr
+}
+
+// apiClientBlockSchema returns the schema shared by the read, write and opl
+// blocks.
+func apiClientBlockSchema(urlEnv string) *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"url": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc(urlEnv, nil),
+			},
+			"headers": {
+				Type:      schema.TypeMap,
+				Optional:  true,
+				Sensitive: true,
+			},
+			"ca_cert": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"client_cert": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"client_key": {
+				Type:      schema.TypeString,
+				Optional:  true,
+				Sensitive: true,
+			},
+			"insecure_skip_verify": {
+				Type:     schema.TypeBool,
+				Optional: true,
+			},
+		},
+	}
+}
+
+// providerSettings is the provider configuration independent of the plugin
+// SDK it was read with, the SDKv2 and framework providers both build their
+// clients from it.
+type providerSettings struct {
+	protocol string
+	// url, projectSlug and sdkUrl only hold the configured values, their
+	// environment variables are applied by newProviderConfig
+	url                  string
+	projectSlug          string
+	sdkUrl               string
+	apiKey               string
+	validateNamespaces   bool
+	requestTimeout       string
+	maxRetries           int
+	minBackoff           string
+	maxBackoff           string
+	retryableStatusCodes []int
+	workspaceApiKey      string
+	consoleUrl           string
+	// read, write and opl are nil when the block is omitted
+	read  *apiClientSettings
+	write *apiClientSettings
+	opl   *apiClientSettings
+}
+
+// apiClientSettings holds the settings of a read, write or opl block.
+type apiClientSettings struct {
+	url                string
+	headers            map[string]string
+	caCert             string
+	clientCert         string
+	clientKey          string
+	insecureSkipVerify bool
+}
+
 func configureProvider(ctx context.Context, d *schema.ResourceData) (interface{}, diag.Diagnostics) {
-	readObject := d.Get("read").([]interface{})[0].(map[string]interface{})
-	readUrl, err := url.Parse(readObject["url"].(string))
+	config, err := newProviderConfig(getProviderSettings(d))
 	if err != nil {
-		return nil, diag.Errorf("parse keto url: %v", err)
+		return nil, diag.FromErr(err)
 	}
+	return config, diag.Diagnostics{}
+}
 
-	confReadHeaders := readObject["headers"].(map[string]interface{})
-	readHeaders := make(map[string]string)
-	for k, v := range confReadHeaders {
-		readHeaders[k] = v.(string)
+func getProviderSettings(d *schema.ResourceData) providerSettings {
+	settings := providerSettings{
+		protocol:           d.Get("protocol").(string),
+		url:                d.Get("url").(string),
+		projectSlug:        d.Get("project_slug").(string),
+		sdkUrl:             d.Get("sdk_url").(string),
+		apiKey:             d.Get("api_key").(string),
+		validateNamespaces: d.Get("validate_namespaces").(bool),
+		requestTimeout:     d.Get("request_timeout").(string),
+		maxRetries:         d.Get("max_retries").(int),
+		minBackoff:         d.Get("min_backoff").(string),
+		maxBackoff:         d.Get("max_backoff").(string),
+		workspaceApiKey:    d.Get("workspace_api_key").(string),
+		consoleUrl:         d.Get("console_url").(string),
+		read:               getApiClientSettings(d, "read"),
+		write:              getApiClientSettings(d, "write"),
+		opl:                getApiClientSettings(d, "opl"),
+	}
+	for _, code := range d.Get("retryable_status_codes").(*schema.Set).List() {
+		settings.retryableStatusCodes = append(settings.retryableStatusCodes, code.(int))
 	}
+	return settings
+}
 
-	httpReadClient := cleanhttp.DefaultClient()
-	readClientConfig := keto.NewConfiguration()
-	readClientConfig.Host = readUrl.Host
-	readClientConfig.Scheme = readUrl.Scheme
-	readClientConfig.DefaultHeader = readHeaders
-	readClientConfig.UserAgent = "terraform/ory-keto-provider"
-	readClientConfig.Debug = false
-	readClientConfig.HTTPClient = httpReadClient
-	readApiClient := keto.NewAPIClient(readClientConfig)
+func getApiClientSettings(d *schema.ResourceData, key string) *apiClientSettings {
+	blocks := d.Get(key).([]interface{})
+	if len(blocks) == 0 || blocks[0] == nil {
+		return nil
+	}
+	block := blocks[0].(map[string]interface{})
+	settings := &apiClientSettings{
+		url:                block["url"].(string),
+		headers:            make(map[string]string),
+		caCert:             block["ca_cert"].(string),
+		clientCert:         block["client_cert"].(string),
+		clientKey:          block["client_key"].(string),
+		insecureSkipVerify: block["insecure_skip_verify"].(bool),
+	}
+	for k, v := range block["headers"].(map[string]interface{}) {
+		settings.headers[k] = v.(string)
+	}
+	return settings
+}
 
-	writeObject := d.Get("write").([]interface{})[0].(map[string]interface{})
-	writeUrl, err := url.Parse(writeObject["url"].(string))
+func newProviderConfig(settings providerSettings) (*providerConfig, error) {
+	baseUrl, err := getBaseUrl(settings)
 	if err != nil {
-		return nil, diag.Errorf("parse keto url: %v", err)
+		return nil, err
+	}
+
+	baseHeaders := make(map[string]string)
+	if settings.apiKey != "" {
+		baseHeaders["Authorization"] = "Bearer " + settings.apiKey
 	}
 
-	confWriteHeaders := writeObject["headers"].(map[string]interface{})
-	writeHeaders := make(map[string]string)
-	for k, v := range confWriteHeaders {
-		writeHeaders[k] = v.(string)
+	retry := getRetryConfig(settings)
+	// validated in the schema, zero means no timeout
+	requestTimeout, _ := time.ParseDuration(settings.requestTimeout)
+
+	readConfig := getApiClientConfig(settings.read, readUrlEnv, baseUrl, baseHeaders)
+	readConfig.retry = retry
+	readConfig.timeout = requestTimeout
+	if readConfig.url == "" {
+		return nil, errors.New("keto read url must be set through the read block, url, project_slug or sdk_url")
+	}
+
+	writeConfig := getApiClientConfig(settings.write, writeUrlEnv, baseUrl, baseHeaders)
+	writeConfig.retry = retry
+	writeConfig.timeout = requestTimeout
+	if writeConfig.url == "" {
+		return nil, errors.New("keto write url must be set through the write block, url, project_slug or sdk_url")
+	}
+
+	// self-hosted Keto serves the OPL syntax check on its own port, without
+	// any opl url the read API is used, e.g. behind a gateway
+	oplConfig := getApiClientConfig(settings.opl, oplUrlEnv, baseUrl, baseHeaders)
+	oplConfig.retry = retry
+	oplConfig.timeout = requestTimeout
+	if oplConfig.url == "" {
+		oplConfig = readConfig
+	}
+
+	var console *oryConsoleClient
+	if settings.workspaceApiKey != "" {
+		httpClient := cleanhttp.DefaultClient()
+		httpClient.Transport = newRetryTransport(httpClient.Transport, retry, requestTimeout)
+		console = &oryConsoleClient{
+			url:        strings.TrimSuffix(settings.consoleUrl, "/"),
+			apiKey:     settings.workspaceApiKey,
+			httpClient: httpClient,
+		}
 	}
 
-	httpWriteClient := cleanhttp.DefaultClient()
-	writeClientConfig := keto.NewConfiguration()
-	writeClientConfig.Host = writeUrl.Host
-	writeClientConfig.Scheme = writeUrl.Scheme
-	writeClientConfig.DefaultHeader = writeHeaders
-	writeClientConfig.UserAgent = "terraform/ory-keto-provider"
-	writeClientConfig.Debug = false
-	writeClientConfig.HTTPClient = httpWriteClient
-	writeApiClient := keto.NewAPIClient(writeClientConfig)
+	if settings.protocol == protocolGrpc {
+		backend, err := newGrpcBackend(readConfig, writeConfig, oplConfig)
+		if err != nil {
+			return nil, err
+		}
+		return &providerConfig{
+			backend:            backend,
+			console:            console,
+			validateNamespaces: settings.validateNamespaces,
+		}, nil
+	}
+
+	readApiClient, err := newApiClient(readConfig)
+	if err != nil {
+		return nil, err
+	}
+	writeApiClient, err := newApiClient(writeConfig)
+	if err != nil {
+		return nil, err
+	}
+	oplApiClient, err := newApiClient(oplConfig)
+	if err != nil {
+		return nil, err
+	}
 
 	return &providerConfig{
-		readApiClient:  readApiClient,
-		writeApiClient: writeApiClient,
-	}, diag.Diagnostics{}
+		backend: &restBackend{
+			readApiClient:  readApiClient,
+			writeApiClient: writeApiClient,
+			oplApiClient:   oplApiClient,
+		},
+		console:            console,
+		validateNamespaces: settings.validateNamespaces,
+	}, nil
+}
+
+// getBaseUrl returns the url shared by all clients from url, project_slug or
+// sdk_url. Only one of them can be configured, their environment variables
+// are used in the same order when none is.
+func getBaseUrl(settings providerSettings) (string, error) {
+	var configured []string
+	if settings.url != "" {
+		configured = append(configured, "url")
+	}
+	if settings.projectSlug != "" {
+		configured = append(configured, "project_slug")
+	}
+	if settings.sdkUrl != "" {
+		configured = append(configured, "sdk_url")
+	}
+	if len(configured) > 1 {
+		return "", fmt.Errorf("only one of url, project_slug or sdk_url can be set, got %s", strings.Join(configured, ", "))
+	}
+
+	ketoUrl, projectSlug, sdkUrl := settings.url, settings.projectSlug, settings.sdkUrl
+	if len(configured) == 0 {
+		ketoUrl, projectSlug, sdkUrl = os.Getenv(urlEnv), os.Getenv(projectSlugEnv), os.Getenv(sdkUrlEnv)
+	}
+	switch {
+	case ketoUrl != "":
+		return ketoUrl, nil
+	case projectSlug != "":
+		return fmt.Sprintf(oryNetworkProjectUrlFormat, projectSlug), nil
+	default:
+		return sdkUrl, nil
+	}
+}
+
+// getApiClientConfig merges the read, write or opl block settings on top of
+// the base url and headers shared by all clients. When the block is omitted the
+// block url environment variable still takes precedence over the base url.
+func getApiClientConfig(block *apiClientSettings, urlEnv string, baseUrl string, baseHeaders map[string]string) apiClientConfig {
+	config := apiClientConfig{
+		url:     baseUrl,
+		headers: make(map[string]string),
+	}
+	for k, v := range baseHeaders {
+		config.headers[k] = v
+	}
+
+	if block == nil {
+		if envUrl := os.Getenv(urlEnv); envUrl != "" {
+			config.url = envUrl
+		}
+		return config
+	}
+	if block.url != "" {
+		config.url = block.url
+	}
+	for k, v := range block.headers {
+		config.headers[k] = v
+	}
+	config.caCert = block.caCert
+	config.clientCert = block.clientCert
+	config.clientKey = block.clientKey
+	config.insecureSkipVerify = block.insecureSkipVerify
+	return config
+}
+
+func getRetryConfig(settings providerSettings) retryConfig {
+	// durations are validated in the schema
+	minBackoff, _ := time.ParseDuration(settings.minBackoff)
+	maxBackoff, _ := time.ParseDuration(settings.maxBackoff)
+
+	statusCodes := make(map[int]bool)
+	for _, code := range settings.retryableStatusCodes {
+		statusCodes[code] = true
+	}
+	if len(statusCodes) == 0 {
+		for _, code := range defaultRetryableStatusCodes {
+			statusCodes[code] = true
+		}
+	}
+
+	return retryConfig{
+		maxRetries:           settings.maxRetries,
+		minBackoff:           minBackoff,
+		maxBackoff:           maxBackoff,
+		retryableStatusCodes: statusCodes,
+	}
+}
+
+func newApiClient(config apiClientConfig) (*keto.APIClient, error) {
+	ketoUrl, err := url.Parse(config.url)
+	if err != nil {
+		return nil, fmt.Errorf("parse keto url: %v", err)
+	}
+
+	tlsConfig, err := newTLSConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	httpClient := cleanhttp.DefaultClient()
+	if tlsConfig != nil {
+		httpClient.Transport.(*http.Transport).TLSClientConfig = tlsConfig
+	}
+	httpClient.Transport = newRetryTransport(httpClient.Transport, config.retry, config.timeout)
+	clientConfig := keto.NewConfiguration()
+	clientConfig.Host = ketoUrl.Host
+	clientConfig.Scheme = ketoUrl.Scheme
+	clientConfig.DefaultHeader = config.headers
+	clientConfig.UserAgent = "terraform/ory-keto-provider"
+	clientConfig.Debug = false
+	clientConfig.HTTPClient = httpClient
+	return keto.NewAPIClient(clientConfig), nil
+}
+
+func validateDuration(v interface{}, k string) ([]string, []error) {
+	if _, err := time.ParseDuration(v.(string)); err != nil {
+		return nil, []error{fmt.Errorf("%s: %v", k, err)}
+	}
+	return nil, nil
 }
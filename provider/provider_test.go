@@ -0,0 +1,356 @@
+package provider
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	fwprovider "github.com/hashicorp/terraform-plugin-framework/provider"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestGetBaseUrl(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		settings providerSettings
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "url",
+			settings: providerSettings{url: "http://keto:4466"},
+			want:     "http://keto:4466",
+		},
+		{
+			name:     "project slug",
+			settings: providerSettings{projectSlug: "slug"},
+			want:     "https://slug.projects.oryapis.com",
+		},
+		{
+			name:     "sdk url",
+			settings: providerSettings{sdkUrl: "https://auth.example.com"},
+			want:     "https://auth.example.com",
+		},
+		{
+			name:     "url environment variable",
+			env:      map[string]string{urlEnv: "http://keto:4466"},
+			settings: providerSettings{},
+			want:     "http://keto:4466",
+		},
+		{
+			name:     "environment variables order",
+			env:      map[string]string{projectSlugEnv: "slug", sdkUrlEnv: "https://auth.example.com"},
+			settings: providerSettings{},
+			want:     "https://slug.projects.oryapis.com",
+		},
+		{
+			name:     "configured project slug over url environment variable",
+			env:      map[string]string{urlEnv: "http://keto:4466"},
+			settings: providerSettings{projectSlug: "slug"},
+			want:     "https://slug.projects.oryapis.com",
+		},
+		{
+			name:     "configured url over project slug environment variable",
+			env:      map[string]string{projectSlugEnv: "slug"},
+			settings: providerSettings{url: "http://keto:4466"},
+			want:     "http://keto:4466",
+		},
+		{
+			name:     "configured url and project slug",
+			settings: providerSettings{url: "http://keto:4466", projectSlug: "slug"},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, env := range []string{urlEnv, projectSlugEnv, sdkUrlEnv} {
+				t.Setenv(env, tt.env[env])
+			}
+			got, err := getBaseUrl(tt.settings)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProviderConfigureUrlEnvironmentVariable(t *testing.T) {
+	t.Setenv(urlEnv, "http://keto:4466")
+	t.Setenv(projectSlugEnv, "")
+	t.Setenv(sdkUrlEnv, "")
+
+	provider := Provider(context.Background())
+	config := terraform.NewResourceConfigRaw(map[string]interface{}{
+		"project_slug": "slug",
+	})
+	if diags := provider.Validate(config); diags.HasError() {
+		t.Fatalf("got diagnostics %v, want none", diags)
+	}
+	if diags := provider.Configure(context.Background(), config); diags.HasError() {
+		t.Fatalf("got diagnostics %v, want none", diags)
+	}
+
+	backend := provider.Meta().(*providerConfig).backend.(*restBackend)
+	if got := backend.readApiClient.GetConfig().Host; got != "slug.projects.oryapis.com" {
+		t.Fatalf("got host %q, want the project slug over the url environment variable", got)
+	}
+}
+
+func TestGetApiClientConfig(t *testing.T) {
+	baseHeaders := map[string]string{"Authorization": "Bearer key"}
+	tests := []struct {
+		name        string
+		env         string
+		block       *apiClientSettings
+		wantUrl     string
+		wantHeaders map[string]string
+	}{
+		{
+			name:        "no block",
+			wantUrl:     "http://keto:4466",
+			wantHeaders: map[string]string{"Authorization": "Bearer key"},
+		},
+		{
+			name:        "no block with environment variable",
+			env:         "http://keto-read:4466",
+			wantUrl:     "http://keto-read:4466",
+			wantHeaders: map[string]string{"Authorization": "Bearer key"},
+		},
+		{
+			name:        "block without url",
+			block:       &apiClientSettings{headers: map[string]string{"X-Tenant": "a"}},
+			wantUrl:     "http://keto:4466",
+			wantHeaders: map[string]string{"Authorization": "Bearer key", "X-Tenant": "a"},
+		},
+		{
+			name:        "block url",
+			env:         "http://keto-read:4466",
+			block:       &apiClientSettings{url: "http://keto-block:4466"},
+			wantUrl:     "http://keto-block:4466",
+			wantHeaders: map[string]string{"Authorization": "Bearer key"},
+		},
+		{
+			name:        "block headers over api key",
+			block:       &apiClientSettings{headers: map[string]string{"Authorization": "Basic token"}},
+			wantUrl:     "http://keto:4466",
+			wantHeaders: map[string]string{"Authorization": "Basic token"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(readUrlEnv, tt.env)
+			config := getApiClientConfig(tt.block, readUrlEnv, "http://keto:4466", baseHeaders)
+			if config.url != tt.wantUrl {
+				t.Fatalf("got url %q, want %q", config.url, tt.wantUrl)
+			}
+			if !reflect.DeepEqual(config.headers, tt.wantHeaders) {
+				t.Fatalf("got headers %v, want %v", config.headers, tt.wantHeaders)
+			}
+		})
+	}
+}
+
+func TestNewProviderConfigUrls(t *testing.T) {
+	tests := []struct {
+		name      string
+		settings  providerSettings
+		wantRead  string
+		wantWrite string
+		wantOpl   string
+		wantErr   bool
+	}{
+		{
+			name:      "shared url",
+			settings:  providerSettings{url: "http://keto:4466"},
+			wantRead:  "keto:4466",
+			wantWrite: "keto:4466",
+			wantOpl:   "keto:4466",
+		},
+		{
+			name: "blocks",
+			settings: providerSettings{
+				read:  &apiClientSettings{url: "http://keto:4466"},
+				write: &apiClientSettings{url: "http://keto:4467"},
+				opl:   &apiClientSettings{url: "http://keto:4469"},
+			},
+			wantRead:  "keto:4466",
+			wantWrite: "keto:4467",
+			wantOpl:   "keto:4469",
+		},
+		{
+			name: "write block over shared url",
+			settings: providerSettings{
+				url:   "http://keto:4466",
+				write: &apiClientSettings{url: "http://keto:4467"},
+			},
+			wantRead:  "keto:4466",
+			wantWrite: "keto:4467",
+			wantOpl:   "keto:4466",
+		},
+		{
+			name:     "no write url",
+			settings: providerSettings{read: &apiClientSettings{url: "http://keto:4466"}},
+			wantErr:  true,
+		},
+		{
+			name:    "no url",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, env := range []string{urlEnv, projectSlugEnv, sdkUrlEnv, readUrlEnv, writeUrlEnv, oplUrlEnv} {
+				t.Setenv(env, "")
+			}
+			tt.settings.protocol = protocolRest
+			config, err := newProviderConfig(tt.settings)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			backend := config.backend.(*restBackend)
+			got := []string{backend.readApiClient.GetConfig().Host, backend.writeApiClient.GetConfig().Host, backend.oplApiClient.GetConfig().Host}
+			if want := []string{tt.wantRead, tt.wantWrite, tt.wantOpl}; !reflect.DeepEqual(got, want) {
+				t.Fatalf("got read, write and opl hosts %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+// frameworkProviderSettings returns the settings the framework provider
+// builds from the raw configuration, which holds strings, ints, bools, int
+// slices for sets and slices of maps for blocks.
+func frameworkProviderSettings(t *testing.T, raw map[string]interface{}) providerSettings {
+	t.Helper()
+	ctx := context.Background()
+	var schemaResp fwprovider.SchemaResponse
+	NewFrameworkProvider().Schema(ctx, fwprovider.SchemaRequest{}, &schemaResp)
+
+	objectType := schemaResp.Schema.Type().TerraformType(ctx).(tftypes.Object)
+	config := tfsdk.Config{Schema: schemaResp.Schema, Raw: tfValue(t, objectType, raw)}
+	var model frameworkProviderModel
+	if diags := config.Get(ctx, &model); diags.HasError() {
+		t.Fatal(diags)
+	}
+	settings, diags := model.settings(ctx)
+	if diags.HasError() {
+		t.Fatal(diags)
+	}
+	return settings
+}
+
+func tfValue(t *testing.T, valueType tftypes.Type, raw interface{}) tftypes.Value {
+	t.Helper()
+	if raw == nil {
+		return tftypes.NewValue(valueType, nil)
+	}
+	switch valueType := valueType.(type) {
+	case tftypes.Object:
+		values := make(map[string]tftypes.Value, len(valueType.AttributeTypes))
+		for name, attributeType := range valueType.AttributeTypes {
+			if value, ok := raw.(map[string]interface{})[name]; ok {
+				values[name] = tfValue(t, attributeType, value)
+			} else if _, ok := attributeType.(tftypes.List); ok {
+				// omitted blocks are empty lists
+				values[name] = tftypes.NewValue(attributeType, []tftypes.Value{})
+			} else {
+				values[name] = tftypes.NewValue(attributeType, nil)
+			}
+		}
+		return tftypes.NewValue(valueType, values)
+	case tftypes.List:
+		var values []tftypes.Value
+		for _, value := range raw.([]interface{}) {
+			values = append(values, tfValue(t, valueType.ElementType, value))
+		}
+		return tftypes.NewValue(valueType, values)
+	case tftypes.Set:
+		var values []tftypes.Value
+		for _, value := range raw.([]interface{}) {
+			values = append(values, tfValue(t, valueType.ElementType, value))
+		}
+		return tftypes.NewValue(valueType, values)
+	case tftypes.Map:
+		values := make(map[string]tftypes.Value)
+		for k, value := range raw.(map[string]interface{}) {
+			values[k] = tfValue(t, valueType.ElementType, value)
+		}
+		return tftypes.NewValue(valueType, values)
+	default:
+		return tftypes.NewValue(valueType, raw)
+	}
+}
+
+func TestProviderSettingsParity(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		raw  map[string]interface{}
+	}{
+		{
+			name: "defaults",
+			raw:  map[string]interface{}{},
+		},
+		{
+			name: "environment variables",
+			env: map[string]string{
+				urlEnv:             "http://keto:4466",
+				apiKeyEnv:          "key",
+				workspaceApiKeyEnv: "workspace-key",
+				consoleUrlEnv:      "https://console.example.com",
+			},
+			raw: map[string]interface{}{},
+		},
+		{
+			name: "configured over environment variables",
+			env: map[string]string{
+				apiKeyEnv:     "key",
+				consoleUrlEnv: "https://console.example.com",
+				readUrlEnv:    "http://keto-env:4466",
+			},
+			raw: map[string]interface{}{
+				"protocol":               "grpc",
+				"project_slug":           "slug",
+				"api_key":                "configured-key",
+				"validate_namespaces":    true,
+				"request_timeout":        "10s",
+				"max_retries":            0,
+				"min_backoff":            "2s",
+				"max_backoff":            "1m",
+				"retryable_status_codes": []interface{}{503},
+				"console_url":            "https://console.ory.sh",
+				"read":                   []interface{}{map[string]interface{}{"headers": map[string]interface{}{"X-Tenant": "a"}}},
+				"write": []interface{}{map[string]interface{}{
+					"url":                  "http://keto:4467",
+					"ca_cert":              "/etc/keto/ca.pem",
+					"client_cert":          "/etc/keto/cert.pem",
+					"client_key":           "/etc/keto/key.pem",
+					"insecure_skip_verify": true,
+				}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, env := range []string{urlEnv, projectSlugEnv, sdkUrlEnv, apiKeyEnv, workspaceApiKeyEnv, consoleUrlEnv, readUrlEnv, writeUrlEnv, oplUrlEnv} {
+				t.Setenv(env, tt.env[env])
+			}
+
+			d := schema.TestResourceDataRaw(t, Provider(context.Background()).Schema, tt.raw)
+			sdkSettings := getProviderSettings(d)
+			frameworkSettings := frameworkProviderSettings(t, tt.raw)
+			if !reflect.DeepEqual(sdkSettings, frameworkSettings) {
+				t.Fatalf("got SDKv2 settings %+v, framework settings %+v, want them equal", sdkSettings, frameworkSettings)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	ketoClient "github.com/ory/keto-client-go"
+	"github.com/ory/keto/ketoapi"
+)
+
+func getRelationshipsForTuple(ctx context.Context, provider *providerConfig, rel *ketoClient.Relationship) ([]ketoClient.Relationship, error) {
+	if rel.SubjectId == nil && rel.SubjectSet == nil {
+		return nil, errors.New("subject_id or subject_set must be set")
+	}
+
+	relationships, err := getAllRelationships(ctx, provider, relationshipToRelationQuery(*rel), "")
+	if err != nil {
+		return nil, err
+	}
+
+	deduplicatedRelationships := deduplicateRelationTuple(relationships)
+	tflog.Debug(ctx, fmt.Sprintf("deduplicated tuples %d", len(deduplicatedRelationships)), nil)
+
+	if len(deduplicatedRelationships) > 1 {
+		return nil, errors.New("multiple relationships found")
+	}
+
+	return deduplicatedRelationships, nil
+}
+
+// getAllRelationships executes the query following next_page_token until all
+// pages are read, an empty snaptoken reads the snapshot picked by the server.
+func getAllRelationships(ctx context.Context, provider *providerConfig, query ketoClient.RelationQuery, snaptoken string) ([]ketoClient.Relationship, error) {
+	var relationships []ketoClient.Relationship
+	pageToken := ""
+	for {
+		readData, err := provider.backend.getRelationships(ctx, query, pageToken, 1000, snaptoken)
+		if err != nil {
+			return nil, err
+		}
+		relationships = append(relationships, readData.RelationTuples...)
+		pageToken = readData.GetNextPageToken()
+		if pageToken == "" {
+			break
+		}
+	}
+	tflog.Debug(ctx, fmt.Sprintf("read %d tuples", len(relationships)), nil)
+	return relationships, nil
+}
+
+func relationshipToRelationQuery(rel ketoClient.Relationship) ketoClient.RelationQuery {
+	return ketoClient.RelationQuery{
+		Namespace:  &rel.Namespace,
+		Object:     &rel.Object,
+		Relation:   &rel.Relation,
+		SubjectId:  rel.SubjectId,
+		SubjectSet: rel.SubjectSet,
+	}
+}
+
+func deduplicateRelationTuple(relationships []ketoClient.Relationship) []ketoClient.Relationship {
+	keys := make(map[string]bool)
+	var list []ketoClient.Relationship
+	for _, entry := range relationships {
+		key := ketoRelationshipToRelationTuple(entry).String()
+		if entry.SubjectSet != nil {
+			key += entry.SubjectSet.Namespace + entry.SubjectSet.Object + entry.SubjectSet.Relation
+		}
+		if _, value := keys[key]; !value {
+			keys[key] = true
+			list = append(list, entry)
+		}
+	}
+	return list
+}
+
+func ketoRelationshipToRelationTuple(d ketoClient.Relationship) *ketoapi.RelationTuple {
+	relationTuple := ketoapi.RelationTuple{
+		Namespace:  d.Namespace,
+		Object:     d.Object,
+		Relation:   d.Relation,
+		SubjectID:  d.SubjectId,
+		SubjectSet: nil,
+	}
+	if d.SubjectSet != nil {
+		relationTuple.SubjectSet = &ketoapi.SubjectSet{
+			Namespace: d.SubjectSet.Namespace,
+			Object:    d.SubjectSet.Object,
+			Relation:  d.SubjectSet.Relation,
+		}
+	}
+	return &relationTuple
+}
+
+func ketoRelationTupleToRelationship(d *ketoapi.RelationTuple) ketoClient.Relationship {
+	relationship := ketoClient.Relationship{
+		Namespace: d.Namespace,
+		Object:    d.Object,
+		Relation:  d.Relation,
+		SubjectId: d.SubjectID,
+	}
+	if d.SubjectSet != nil {
+		relationship.SubjectSet = &ketoClient.SubjectSet{
+			Namespace: d.SubjectSet.Namespace,
+			Object:    d.SubjectSet.Object,
+			Relation:  d.SubjectSet.Relation,
+		}
+	}
+	return relationship
+}
+
+func stringToRelationTuple(s string) (*ketoapi.RelationTuple, error) {
+	return (&ketoapi.RelationTuple{}).FromString(s)
+}
+
+// stringToRelationTuples parses newline delimited relationship text notation,
+// blank lines are skipped.
+func stringToRelationTuples(s string) ([]*ketoapi.RelationTuple, error) {
+	var relationshipTuples []*ketoapi.RelationTuple
+	for _, relString := range strings.Split(s, "\n") {
+		cleanRelString := strings.TrimSpace(relString)
+		if cleanRelString == "" {
+			continue
+		}
+		rt, err := stringToRelationTuple(cleanRelString)
+		if err != nil {
+			return nil, err
+		}
+		relationshipTuples = append(relationshipTuples, rt)
+	}
+	return relationshipTuples, nil
+}
+
+func flattenRelationTupleToJsonList(rt []*ketoapi.RelationTuple) ([]string, error) {
+	flatten := make([]string, len(rt))
+	for i, rt := range rt {
+		b, err := json.Marshal(rt)
+		if err != nil {
+			return nil, err
+		}
+		flatten[i] = string(b)
+	}
+	return flatten, nil
+}
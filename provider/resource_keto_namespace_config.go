@@ -0,0 +1,135 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"time"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func resourceKetoNamespaceConfig() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceKetoNamespaceConfigCreate,
+		ReadContext:   resourceKetoNamespaceConfigRead,
+		UpdateContext: resourceKetoNamespaceConfigUpdate,
+		DeleteContext: resourceKetoNamespaceConfigDelete,
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
+		Schema: map[string]*schema.Schema{
+			"opl": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"project_id": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				ExactlyOneOf: []string{"project_id", "file_path"},
+			},
+			"file_path": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				ExactlyOneOf: []string{"project_id", "file_path"},
+			},
+		},
+	}
+}
+
+func resourceKetoNamespaceConfigCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	if err := writeNamespaceConfig(ctx, d, m.(*providerConfig)); err != nil {
+		return ketoDiagnostics(err, cty.GetAttrPath("opl"))
+	}
+
+	if projectId, ok := d.GetOk("project_id"); ok {
+		d.SetId(projectId.(string))
+	} else {
+		d.SetId(d.Get("file_path").(string))
+	}
+	return resourceKetoNamespaceConfigRead(ctx, d, m)
+}
+
+func resourceKetoNamespaceConfigRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	provider := m.(*providerConfig)
+
+	var opl string
+	if projectId, ok := d.GetOk("project_id"); ok {
+		if provider.console == nil {
+			return diag.FromErr(errOryConsoleNotConfigured)
+		}
+		projectOpl, found, err := provider.console.getNamespacesOpl(ctx, projectId.(string))
+		if err != nil {
+			return ketoDiagnostics(err, cty.GetAttrPath("project_id"))
+		}
+		if !found {
+			d.SetId("")
+			return nil
+		}
+		opl = projectOpl
+	} else {
+		content, err := os.ReadFile(d.Get("file_path").(string))
+		if errors.Is(err, fs.ErrNotExist) {
+			d.SetId("")
+			return nil
+		}
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		opl = string(content)
+	}
+
+	if err := d.Set("opl", opl); err != nil {
+		return diag.FromErr(err)
+	}
+	return nil
+}
+
+func resourceKetoNamespaceConfigUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	if err := writeNamespaceConfig(ctx, d, m.(*providerConfig)); err != nil {
+		return ketoDiagnostics(err, cty.GetAttrPath("opl"))
+	}
+	return resourceKetoNamespaceConfigRead(ctx, d, m)
+}
+
+func resourceKetoNamespaceConfigDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	// Keto can't run without namespaces, and the file may be shared with the
+	// rest of its configuration, so both are left in place
+	if _, ok := d.GetOk("project_id"); ok {
+		return diag.Diagnostics{
+			{
+				Severity: diag.Warning,
+				Summary:  "Ory Network namespace configuration left in place",
+				Detail:   "The resource was removed from state, the project keeps its current Ory Permission Language configuration.",
+			},
+		}
+	}
+	return diag.Diagnostics{
+		{
+			Severity: diag.Warning,
+			Summary:  "Namespaces file left in place",
+			Detail:   "The resource was removed from state, Keto keeps reading " + d.Get("file_path").(string) + ".",
+		},
+	}
+}
+
+// writeNamespaceConfig pushes the OPL document to the Ory Network project, or
+// writes it to the namespaces file watched by a self-hosted Keto.
+func writeNamespaceConfig(ctx context.Context, d *schema.ResourceData, provider *providerConfig) error {
+	opl := d.Get("opl").(string)
+	if projectId, ok := d.GetOk("project_id"); ok {
+		if provider.console == nil {
+			return errOryConsoleNotConfigured
+		}
+		return provider.console.setNamespacesOpl(ctx, projectId.(string), opl)
+	}
+	return os.WriteFile(d.Get("file_path").(string), []byte(opl), 0644)
+}
@@ -0,0 +1,37 @@
+package provider
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestNamespaceConfigDeleteKeepsFile(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "namespaces.keto.ts")
+	opl := "class User implements Namespace {}\n"
+	if err := os.WriteFile(filePath, []byte(opl), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	d := schema.TestResourceDataRaw(t, resourceKetoNamespaceConfig().Schema, map[string]interface{}{
+		"file_path": filePath,
+		"opl":       opl,
+	})
+	d.SetId(filePath)
+
+	diags := resourceKetoNamespaceConfigDelete(context.Background(), d, &providerConfig{})
+	if diags.HasError() || len(diags) != 1 || diags[0].Severity != diag.Warning {
+		t.Fatalf("got diagnostics %v, want a single warning", diags)
+	}
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("got %v reading the namespaces file, want it left in place", err)
+	}
+	if string(content) != opl {
+		t.Fatalf("got namespaces file %q, want %q", content, opl)
+	}
+}
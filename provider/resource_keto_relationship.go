@@ -4,371 +4,439 @@ import (
 	"context"
 	"errors"
 	"fmt"
-
-	"github.com/hashicorp/terraform-plugin-log/tflog"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	rschema "github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 	ketoClient "github.com/ory/keto-client-go"
 	"github.com/ory/keto/ketoapi"
 )
 
-func resourceKetoRelationship() *schema.Resource {
-	return &schema.Resource{
-		CreateContext: resourceKetoRelationshipCreate,
-		ReadContext:   resourceKetoRelationshipRead,
-		DeleteContext: resourceKetoRelationshipDelete,
-		Importer: &schema.ResourceImporter{
-			StateContext: resourceKetoRelationshipImport,
-		},
-		Schema: map[string]*schema.Schema{
-			"namespace": {
-				Type:     schema.TypeString,
-				Optional: true,
-				ForceNew: true,
-			},
-			"object": {
-				Type:     schema.TypeString,
-				Optional: true,
-				ForceNew: true,
-			},
-			"relation": {
-				Type:     schema.TypeString,
-				Optional: true,
-				ForceNew: true,
-			},
-			"subject_id": {
-				Type:     schema.TypeString,
-				Optional: true,
-				ForceNew: true,
-			},
-			"subject_set_namespace": {
-				Type:     schema.TypeString,
-				ForceNew: true,
-				Optional: true,
-			},
-			"subject_set_object": {
-				Type:     schema.TypeString,
-				ForceNew: true,
-				Optional: true,
-			},
-			"subject_set_relation": {
-				Type:     schema.TypeString,
-				ForceNew: true,
-				Optional: true,
-			},
+var (
+	_ resource.ResourceWithConfigure    = &relationshipResource{}
+	_ resource.ResourceWithImportState  = &relationshipResource{}
+	_ resource.ResourceWithModifyPlan   = &relationshipResource{}
+	_ resource.ResourceWithUpgradeState = &relationshipResource{}
+)
+
+type relationshipResource struct {
+	provider *providerConfig
+}
+
+type relationshipResourceModel struct {
+	Id         types.String   `tfsdk:"id"`
+	Namespace  types.String   `tfsdk:"namespace"`
+	Object     types.String   `tfsdk:"object"`
+	Relation   types.String   `tfsdk:"relation"`
+	SubjectId  types.String   `tfsdk:"subject_id"`
+	SubjectSet types.Object   `tfsdk:"subject_set"`
+	Snaptoken  types.String   `tfsdk:"snaptoken"`
+	Timeouts   timeouts.Value `tfsdk:"timeouts"`
+}
+
+// relationshipResourceModelV0 is the state written before subject sets were
+// nested, by the SDKv2 implementation and the first framework versions.
+type relationshipResourceModelV0 struct {
+	Id                  types.String   `tfsdk:"id"`
+	Namespace           types.String   `tfsdk:"namespace"`
+	Object              types.String   `tfsdk:"object"`
+	Relation            types.String   `tfsdk:"relation"`
+	SubjectId           types.String   `tfsdk:"subject_id"`
+	SubjectSetNamespace types.String   `tfsdk:"subject_set_namespace"`
+	SubjectSetObject    types.String   `tfsdk:"subject_set_object"`
+	SubjectSetRelation  types.String   `tfsdk:"subject_set_relation"`
+	Snaptoken           types.String   `tfsdk:"snaptoken"`
+	Timeouts            timeouts.Value `tfsdk:"timeouts"`
+}
+
+func newRelationshipResource() resource.Resource {
+	return &relationshipResource{}
+}
+
+func (r *relationshipResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_relationship"
+}
+
+func (r *relationshipResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	attributes := tupleResourceAttributes()
+	attributes["id"] = rschema.StringAttribute{
+		Computed: true,
+	}
+	attributes["snaptoken"] = rschema.StringAttribute{
+		Computed: true,
+	}
+	resp.Schema = rschema.Schema{
+		Version:    1,
+		Attributes: attributes,
+		Blocks: map[string]rschema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Read:   true,
+				Update: true,
+				Delete: true,
+			}),
 		},
 	}
 }
 
-func resourceKetoRelationshipImport(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
-	provider := m.(*providerConfig)
+func (r *relationshipResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	r.provider = req.ProviderData.(*providerConfig)
+}
 
-	id := d.Id()
-	rt, err := stringToRelationTuple(id)
-	if err != nil {
-		return nil, fmt.Errorf("malformed id: %s", err)
+// ModifyPlan verifies namespaces and relation against the server during plan
+// when validate_namespaces is enabled.
+func (r *relationshipResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if r.provider == nil || !r.provider.validateNamespaces || req.Plan.Raw.IsNull() {
+		return
 	}
-	rel := ketoRelationTupleToRelationship(rt)
 
-	existingRelationships, err := getRelationshipsForTuple(ctx, provider, &rel)
-	if err != nil {
-		return nil, err
+	var plan relationshipResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
-	if len(existingRelationships) == 0 {
-		d.SetId("")
-		return nil, fmt.Errorf("relationship '%s' not found, data race suspected", ketoRelationshipToRelationTuple(rel).String())
+	if !plan.tuple().isKnown() {
+		return
 	}
-	relationship := existingRelationships[0]
 
-	if err = d.Set("namespace", relationship.Namespace); err != nil {
-		return nil, err
+	namespaces, err := r.provider.getNamespaces(ctx)
+	if err != nil {
+		resp.Diagnostics.Append(ketoFrameworkDiagnostics(err, path.Empty())...)
+		return
 	}
-	if err = d.Set("object", relationship.Object); err != nil {
-		return nil, err
+
+	rel := plan.relationship()
+	if !namespaces[rel.Namespace] {
+		resp.Diagnostics.AddAttributeError(path.Root("namespace"), fmt.Sprintf("namespace %q does not exist", rel.Namespace), "")
+		return
 	}
-	if err = d.Set("relation", relationship.Relation); err != nil {
-		return nil, err
+	if rel.SubjectSet != nil && !namespaces[rel.SubjectSet.Namespace] {
+		resp.Diagnostics.AddAttributeError(path.Root("subject_set").AtName("namespace"), fmt.Sprintf("subject_set namespace %q does not exist", rel.SubjectSet.Namespace), "")
+		return
 	}
-	if relationship.SubjectId != nil {
-		if err = d.Set("subject_id", *relationship.SubjectId); err != nil {
-			return nil, err
-		}
-	} else if relationship.SubjectSet != nil {
-		if err = d.Set("subject_set_namespace", relationship.SubjectSet.Namespace); err != nil {
-			return nil, err
-		}
-		if err = d.Set("subject_set_object", relationship.SubjectSet.Object); err != nil {
-			return nil, err
+
+	if rel.SubjectSet != nil && rel.SubjectSet.Relation != "" {
+		subjectSetRel := ketoClient.Relationship{
+			Namespace: rel.SubjectSet.Namespace,
+			Object:    rel.SubjectSet.Object,
+			Relation:  rel.SubjectSet.Relation,
+			SubjectSet: &ketoClient.SubjectSet{
+				Namespace: rel.SubjectSet.Namespace,
+				Object:    rel.SubjectSet.Object,
+			},
 		}
-		if err = d.Set("subject_set_relation", relationship.SubjectSet.Relation); err != nil {
-			return nil, err
+		resp.Diagnostics.Append(r.verifyRelation(ctx, subjectSetRel, path.Root("subject_set").AtName("relation"))...)
+		if resp.Diagnostics.HasError() {
+			return
 		}
-	} else {
-		return nil, errors.New("subject_id or subject_set must be set")
 	}
-
-	return schema.ImportStatePassthroughContext(ctx, d, m)
+	resp.Diagnostics.Append(r.verifyRelation(ctx, rel, path.Root("relation"))...)
 }
 
-func resourceKetoRelationshipCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	provider := m.(*providerConfig)
+// verifyRelation verifies that the relation of rel is declared in its
+// namespace by checking rel. Keto fails a check of an undeclared relation
+// with a 500 "relation not found" error, namespaces without OPL accept any
+// relation.
+func (r *relationshipResource) verifyRelation(ctx context.Context, rel ketoClient.Relationship, attributePath path.Path) diag.Diagnostics {
+	_, err := r.provider.backend.checkPermission(ctx, rel, 1, readConsistency{})
+	if err == nil {
+		return nil
+	}
+	var ketoErr *ketoError
+	if !errors.As(err, &ketoErr) || !ketoErr.isUndeclaredRelation(rel.Relation) {
+		return ketoFrameworkDiagnostics(err, path.Empty())
+	}
+
+	var diags diag.Diagnostics
+	_, detail := ketoErr.describe()
+	diags.AddAttributeError(attributePath, fmt.Sprintf("relation %q of namespace %q is not declared", rel.Relation, rel.Namespace), detail)
+	return diags
+}
 
-	if err := validateSchemaRelationTuple(d, ""); err != nil {
-		return diag.FromErr(err)
+func (r *relationshipResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan relationshipResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
-	rel, err := getClientRelationship(d)
-	if err != nil {
-		return diag.FromErr(err)
+	timeout, diags := plan.Timeouts.Create(ctx, 5*time.Minute)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
 
-	existingRelation, err := getRelationshipsForTuple(ctx, provider, &rel)
+	rel := plan.relationship()
+	existingRelationships, err := getRelationshipsForTuple(ctx, r.provider, &rel)
 	if err != nil {
-		return diag.FromErr(err)
+		resp.Diagnostics.Append(ketoFrameworkDiagnostics(err, path.Empty())...)
+		return
 	}
-	if len(existingRelation) == 1 {
-		return diag.FromErr(fmt.Errorf("relationship '%s' already exists", ketoRelationshipToRelationTuple(rel).String()))
-	}
-
-	body := ketoClient.CreateRelationshipBody{
-		Namespace:  &rel.Namespace,
-		Object:     &rel.Object,
-		Relation:   &rel.Relation,
-		SubjectId:  rel.SubjectId,
-		SubjectSet: rel.SubjectSet,
+	if len(existingRelationships) == 1 {
+		resp.Diagnostics.AddError(fmt.Sprintf("relationship '%s' already exists", ketoRelationshipToRelationTuple(rel).String()), "")
+		return
 	}
 
-	// Todo: validate returned relationship
-	_, resp, err := provider.writeApiClient.RelationshipApi.
-		CreateRelationship(nil).
-		CreateRelationshipBody(body).
-		Execute()
+	snaptoken, err := r.provider.backend.createRelationship(ctx, rel)
 	if err != nil {
-		return diag.FromErr(err)
+		resp.Diagnostics.Append(ketoFrameworkDiagnostics(err, path.Empty())...)
+		return
 	}
-	defer func() {
-		if err := resp.Body.Close(); err != nil {
-			diag.FromErr(err)
-		}
-	}()
 
-	if resp.StatusCode != 201 {
-		return diag.Errorf("unexpected status code: %s", resp.Status)
-	}
-	return resourceKetoRelationshipRead(ctx, d, m)
+	plan.Id = types.StringValue(ketoRelationshipToRelationTuple(rel).String())
+	plan.Snaptoken = types.StringValue(snaptoken)
+	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
 }
 
-func resourceKetoRelationshipDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	provider := m.(*providerConfig)
+func (r *relationshipResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state relationshipResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
-	if err := validateSchemaRelationTuple(d, ""); err != nil {
-		return diag.FromErr(err)
+	timeout, diags := state.Timeouts.Read(ctx, 5*time.Minute)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
 
-	rel, err := getClientRelationship(d)
+	rel := state.relationship()
+	existingRelationships, err := getRelationshipsForTuple(ctx, r.provider, &rel)
 	if err != nil {
-		return diag.FromErr(err)
-	}
-	request := provider.writeApiClient.RelationshipApi.
-		DeleteRelationships(ctx).
-		Namespace(rel.Namespace).
-		Object(rel.Object).
-		Relation(rel.Relation)
-
-	if rel.SubjectId != nil {
-		request = request.SubjectId(*rel.SubjectId)
-	} else if rel.SubjectSet != nil {
-		request = request.
-			SubjectSetNamespace(rel.SubjectSet.Namespace).
-			SubjectSetObject(rel.SubjectSet.Object).
-			SubjectSetRelation(rel.SubjectSet.Relation)
-	} else {
-		return diag.Errorf("subject_id or subject_set must be set")
-	}
-
-	resp, err := request.Execute()
-	if err != nil {
-		return diag.FromErr(err)
+		resp.Diagnostics.Append(ketoFrameworkDiagnostics(err, path.Empty())...)
+		return
 	}
-
-	defer func() {
-		if err := resp.Body.Close(); err != nil {
-			diag.FromErr(err)
-		}
-	}()
-
-	if resp.StatusCode != 204 {
-		return diag.Errorf("unexpected status code: %s", resp.Status)
+	if len(existingRelationships) == 0 {
+		resp.State.RemoveResource(ctx)
+		return
 	}
 
-	return nil
+	state.Id = types.StringValue(ketoRelationshipToRelationTuple(existingRelationships[0]).String())
+	resp.Diagnostics.Append(resp.State.Set(ctx, state)...)
 }
 
-func resourceKetoRelationshipRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	var rel ketoClient.Relationship
-	var err error
+func (r *relationshipResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan, state relationshipResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	timeout, diags := plan.Timeouts.Update(ctx, 5*time.Minute)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
 
-	provider := m.(*providerConfig)
+	newRel := plan.relationship()
+	insert, remove := diffRelationTuples(
+		[]*ketoapi.RelationTuple{ketoRelationshipToRelationTuple(state.relationship())},
+		[]*ketoapi.RelationTuple{ketoRelationshipToRelationTuple(newRel)},
+	)
+	plan.Id = types.StringValue(ketoRelationshipToRelationTuple(newRel).String())
 
-	if err := validateSchemaRelationTuple(d, ""); err != nil {
-		return diag.FromErr(err)
+	// Keto applies the inserts of a patch before its deletes, patching an
+	// unchanged tuple, e.g. when only timeouts change, would delete it
+	if len(insert) == 0 && len(remove) == 0 {
+		plan.Snaptoken = state.Snaptoken
+		resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+		return
 	}
-	rel, err = getClientRelationship(d)
+
+	existingRelationships, err := getRelationshipsForTuple(ctx, r.provider, &newRel)
 	if err != nil {
-		return diag.FromErr(err)
+		resp.Diagnostics.Append(ketoFrameworkDiagnostics(err, path.Empty())...)
+		return
+	}
+	if len(existingRelationships) == 1 {
+		resp.Diagnostics.AddError(fmt.Sprintf("relationship '%s' already exists", ketoRelationshipToRelationTuple(newRel).String()), "")
+		return
 	}
 
-	existingRelationships, err := getRelationshipsForTuple(ctx, provider, &rel)
+	// delete and insert are applied in one transaction, so the permission is
+	// never missing while the tuple is replaced
+	snaptoken, err := patchRelationships(ctx, r.provider, insert, remove)
 	if err != nil {
-		return diag.FromErr(err)
-	}
-	if len(existingRelationships) == 0 {
-		d.SetId("")
-		return nil
+		resp.Diagnostics.Append(ketoFrameworkDiagnostics(err, path.Empty())...)
+		return
 	}
 
-	relationship := existingRelationships[0]
-	setRelationshipId(d, &relationship)
-	return nil
+	plan.Snaptoken = types.StringValue(snaptoken)
+	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
 }
 
-func setRelationshipId(d *schema.ResourceData, rel *ketoClient.Relationship) {
-	d.SetId(ketoRelationshipToRelationTuple(*rel).String())
-}
+func (r *relationshipResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state relationshipResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
-func getRelationshipsForTuple(ctx context.Context, provider *providerConfig, rel *ketoClient.Relationship) ([]ketoClient.Relationship, error) {
-	request := provider.readApiClient.RelationshipApi.
-		GetRelationships(ctx).
-		Namespace(rel.Namespace).
-		Object(rel.Object).
-		Relation(rel.Relation)
-	if rel.SubjectId != nil {
-		request = request.SubjectId(*rel.SubjectId)
-	} else if rel.SubjectSet != nil {
-		request = request.
-			SubjectSetNamespace(rel.SubjectSet.Namespace).
-			SubjectSetObject(rel.SubjectSet.Object).
-			SubjectSetRelation(rel.SubjectSet.Relation)
-	} else {
-		return nil, errors.New("subject_id or subject_set must be set")
-	}
-
-	readData, resp, err := request.
-		PageSize(100). // just in case
-		Execute()
-	if err != nil {
-		if resp != nil && resp.StatusCode == 404 {
-			return nil, nil
-		}
-		return nil, err
+	timeout, diags := state.Timeouts.Delete(ctx, 5*time.Minute)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
-	tflog.Debug(ctx, fmt.Sprintf("read %d tuples", len(readData.RelationTuples)), nil)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
 
-	deduplicatedRelationships := deduplicateRelationTuple(readData.RelationTuples)
-	tflog.Debug(ctx, fmt.Sprintf("deduplicated tuples %d", len(deduplicatedRelationships)), nil)
+	if err := r.provider.backend.deleteRelationships(ctx, relationshipToRelationQuery(state.relationship())); err != nil {
+		resp.Diagnostics.Append(ketoFrameworkDiagnostics(err, path.Empty())...)
+	}
+}
 
-	if len(deduplicatedRelationships) > 1 {
-		return nil, errors.New("multiple relationships found")
+func (r *relationshipResource) UpgradeState(ctx context.Context) map[int64]resource.StateUpgrader {
+	return map[int64]resource.StateUpgrader{
+		0: {
+			PriorSchema: &rschema.Schema{
+				Attributes: map[string]rschema.Attribute{
+					"id": rschema.StringAttribute{
+						Computed: true,
+					},
+					"namespace": rschema.StringAttribute{
+						Required: true,
+					},
+					"object": rschema.StringAttribute{
+						Required: true,
+					},
+					"relation": rschema.StringAttribute{
+						Required: true,
+					},
+					"subject_id": rschema.StringAttribute{
+						Optional: true,
+					},
+					"subject_set_namespace": rschema.StringAttribute{
+						Optional: true,
+					},
+					"subject_set_object": rschema.StringAttribute{
+						Optional: true,
+					},
+					"subject_set_relation": rschema.StringAttribute{
+						Optional: true,
+					},
+					"snaptoken": rschema.StringAttribute{
+						Computed: true,
+					},
+				},
+				Blocks: map[string]rschema.Block{
+					"timeouts": timeouts.Block(ctx, timeouts.Opts{
+						Create: true,
+						Read:   true,
+						Update: true,
+						Delete: true,
+					}),
+				},
+			},
+			StateUpgrader: upgradeRelationshipStateV0,
+		},
 	}
+}
 
-	return deduplicatedRelationships, nil
+// upgradeRelationshipStateV0 moves the flat subject_set_* attributes into the
+// subject_set object. The SDKv2 implementation stored unset subject
+// attributes as empty strings, they are treated as null.
+func upgradeRelationshipStateV0(ctx context.Context, req resource.UpgradeStateRequest, resp *resource.UpgradeStateResponse) {
+	var prior relationshipResourceModelV0
+	resp.Diagnostics.Append(req.State.Get(ctx, &prior)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	state := relationshipResourceModel{
+		Id:         prior.Id,
+		Namespace:  prior.Namespace,
+		Object:     prior.Object,
+		Relation:   prior.Relation,
+		SubjectId:  prior.SubjectId,
+		SubjectSet: subjectSetObjectValue(nil),
+		Snaptoken:  prior.Snaptoken,
+		Timeouts:   prior.Timeouts,
+	}
+	if prior.SubjectId.ValueString() == "" {
+		state.SubjectId = types.StringNull()
+	}
+	if prior.SubjectSetNamespace.ValueString() != "" {
+		state.SubjectSet = subjectSetObjectValue(&ketoClient.SubjectSet{
+			Namespace: prior.SubjectSetNamespace.ValueString(),
+			Object:    prior.SubjectSetObject.ValueString(),
+			Relation:  prior.SubjectSetRelation.ValueString(),
+		})
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, state)...)
 }
 
-func validateSchemaRelationTuple(d *schema.ResourceData, parentKey string) error {
-	if parentKey != "" {
-		if parentKey[len(parentKey)-1] != '.' {
-			parentKey += "."
-		}
-	}
-	_, namespaceOk := d.GetOk(parentKey + "namespace")
-	_, objectOk := d.GetOk(parentKey + "object")
-	_, relationOk := d.GetOk(parentKey + "relation")
-	_, subjectIdOk := d.GetOk("subject_id")
-	_, subjectSetNamespaceOk := d.GetOk("subject_set_namespace")
-	_, subjectSetObjectOk := d.GetOk("subject_set_object")
-	_, subjectSetRelationOk := d.GetOk("subject_set_relation")
-	subjectSetOk := subjectSetNamespaceOk && subjectSetObjectOk && subjectSetRelationOk
-	subjectSetOkAtLeastOne := subjectSetNamespaceOk || subjectSetObjectOk || subjectSetRelationOk
-	if !namespaceOk || !objectOk || !relationOk {
-		return errors.New("namespace, object and relation must be set")
-	}
-	if subjectIdOk && subjectSetOkAtLeastOne {
-		return errors.New("only one of subject_id and subject_set group can be set")
-	} else if !subjectIdOk && !subjectSetOk {
-		if subjectSetOkAtLeastOne {
-			return errors.New("subject_set_namespace, subject_set_object, and subject_set_relation must be defined together")
-		}
-		return errors.New("one of subject_id and subject_set group must be set")
+func (r *relationshipResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	rt, err := stringToRelationTuple(req.ID)
+	if err != nil {
+		resp.Diagnostics.AddError(fmt.Sprintf("malformed id: %s", err), "")
+		return
 	}
-	return nil
-}
+	rel := ketoRelationTupleToRelationship(rt)
 
-func getClientRelationship(d *schema.ResourceData) (ketoClient.Relationship, error) {
-	var relationship ketoClient.Relationship
-	relationship.Namespace = d.Get("namespace").(string)
-	relationship.Object = d.Get("object").(string)
-	relationship.Relation = d.Get("relation").(string)
-	if subjectIdRaw, ok := d.GetOk("subject_id"); ok {
-		subjectId := subjectIdRaw.(string)
-		relationship.SubjectId = &subjectId
-	} else {
-		subjectSet := ketoClient.SubjectSet{
-			Namespace: d.Get("subject_set_namespace").(string),
-			Object:    d.Get("subject_set_object").(string),
-			Relation:  d.Get("subject_set_relation").(string),
-		}
-		relationship.SubjectSet = &subjectSet
+	existingRelationships, err := getRelationshipsForTuple(ctx, r.provider, &rel)
+	if err != nil {
+		resp.Diagnostics.Append(ketoFrameworkDiagnostics(err, path.Empty())...)
+		return
+	}
+	if len(existingRelationships) == 0 {
+		resp.Diagnostics.AddError(fmt.Sprintf("relationship '%s' not found, data race suspected", ketoRelationshipToRelationTuple(rel).String()), "")
+		return
 	}
-	return relationship, nil
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, relationshipResourceModelFrom(existingRelationships[0]))...)
 }
 
-func deduplicateRelationTuple(relationships []ketoClient.Relationship) []ketoClient.Relationship {
-	keys := make(map[string]bool)
-	var list []ketoClient.Relationship
-	for _, entry := range relationships {
-		key := ketoRelationshipToRelationTuple(entry).String()
-		if entry.SubjectSet != nil {
-			key += entry.SubjectSet.Namespace + entry.SubjectSet.Object + entry.SubjectSet.Relation
-		}
-		if _, value := keys[key]; !value {
-			keys[key] = true
-			list = append(list, entry)
-		}
+// tuple returns the relation tuple attributes of the model.
+func (m relationshipResourceModel) tuple() tupleModel {
+	return tupleModel{
+		Namespace:  m.Namespace,
+		Object:     m.Object,
+		Relation:   m.Relation,
+		SubjectId:  m.SubjectId,
+		SubjectSet: m.SubjectSet,
 	}
-	return list
 }
 
-func ketoRelationshipToRelationTuple(d ketoClient.Relationship) *ketoapi.RelationTuple {
-	relationTuple := ketoapi.RelationTuple{
-		Namespace:  d.Namespace,
-		Object:     d.Object,
-		Relation:   d.Relation,
-		SubjectID:  d.SubjectId,
-		SubjectSet: nil,
-	}
-	if d.SubjectSet != nil {
-		relationTuple.SubjectSet = &ketoapi.SubjectSet{
-			Namespace: d.SubjectSet.Namespace,
-			Object:    d.SubjectSet.Object,
-			Relation:  d.SubjectSet.Relation,
-		}
-	}
-	return &relationTuple
+// relationship returns the relationship described by the model.
+func (m relationshipResourceModel) relationship() ketoClient.Relationship {
+	return m.tuple().relationship()
 }
 
-func ketoRelationTupleToRelationship(d *ketoapi.RelationTuple) ketoClient.Relationship {
-	relationship := ketoClient.Relationship{
-		Namespace: d.Namespace,
-		Object:    d.Object,
-		Relation:  d.Relation,
-		SubjectId: d.SubjectID,
-	}
-	if d.SubjectSet != nil {
-		relationship.SubjectSet = &ketoClient.SubjectSet{
-			Namespace: d.SubjectSet.Namespace,
-			Object:    d.SubjectSet.Object,
-			Relation:  d.SubjectSet.Relation,
-		}
+// relationshipResourceModelFrom returns the model of an existing relationship
+// with null values for the subject attributes it doesn't use.
+func relationshipResourceModelFrom(rel ketoClient.Relationship) relationshipResourceModel {
+	tuple := tupleModelFrom(rel)
+	return relationshipResourceModel{
+		Id:         types.StringValue(ketoRelationshipToRelationTuple(rel).String()),
+		Namespace:  tuple.Namespace,
+		Object:     tuple.Object,
+		Relation:   tuple.Relation,
+		SubjectId:  tuple.SubjectId,
+		SubjectSet: tuple.SubjectSet,
+		Snaptoken:  types.StringNull(),
+		Timeouts: timeouts.Value{
+			Object: types.ObjectNull(map[string]attr.Type{
+				"create": types.StringType,
+				"read":   types.StringType,
+				"update": types.StringType,
+				"delete": types.StringType,
+			}),
+		},
 	}
-	return relationship
 }
@@ -0,0 +1,173 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	ketoClient "github.com/ory/keto-client-go"
+)
+
+func TestRelationshipUpgradeStateV0(t *testing.T) {
+	subjectSetType := tftypes.Object{AttributeTypes: map[string]tftypes.Type{
+		"namespace": tftypes.String,
+		"object":    tftypes.String,
+		"relation":  tftypes.String,
+	}}
+	nullSubjectSet := tftypes.NewValue(subjectSetType, nil)
+	subjectSet := func(namespace, object string, relation interface{}) tftypes.Value {
+		return tftypes.NewValue(subjectSetType, map[string]tftypes.Value{
+			"namespace": tftypes.NewValue(tftypes.String, namespace),
+			"object":    tftypes.NewValue(tftypes.String, object),
+			"relation":  tftypes.NewValue(tftypes.String, relation),
+		})
+	}
+	nullString := tftypes.NewValue(tftypes.String, nil)
+
+	tests := []struct {
+		name           string
+		rawState       string
+		wantSubjectId  tftypes.Value
+		wantSubjectSet tftypes.Value
+	}{
+		{
+			name:           "sdk subject id",
+			rawState:       `{"id":"default:app#read@guest","namespace":"default","object":"app","relation":"read","subject_id":"guest","subject_set_namespace":"","subject_set_object":"","subject_set_relation":"","timeouts":null}`,
+			wantSubjectId:  tftypes.NewValue(tftypes.String, "guest"),
+			wantSubjectSet: nullSubjectSet,
+		},
+		{
+			name:           "sdk subject set",
+			rawState:       `{"id":"default:app#write@default:role/admin#member","namespace":"default","object":"app","relation":"write","subject_id":"","subject_set_namespace":"default","subject_set_object":"role/admin","subject_set_relation":"member","timeouts":null}`,
+			wantSubjectId:  nullString,
+			wantSubjectSet: subjectSet("default", "role/admin", "member"),
+		},
+		{
+			name:           "sdk subject set without relation",
+			rawState:       `{"id":"default:app#write@groups:admins","namespace":"default","object":"app","relation":"write","subject_id":"","subject_set_namespace":"groups","subject_set_object":"admins","subject_set_relation":""}`,
+			wantSubjectId:  nullString,
+			wantSubjectSet: subjectSet("groups", "admins", nil),
+		},
+		{
+			name:           "framework subject id",
+			rawState:       `{"id":"default:app#read@guest","namespace":"default","object":"app","relation":"read","subject_id":"guest","subject_set_namespace":null,"subject_set_object":null,"subject_set_relation":null,"snaptoken":"","timeouts":null}`,
+			wantSubjectId:  tftypes.NewValue(tftypes.String, "guest"),
+			wantSubjectSet: nullSubjectSet,
+		},
+		{
+			name:           "framework subject set",
+			rawState:       `{"id":"default:app#write@default:role/admin#member","namespace":"default","object":"app","relation":"write","subject_id":null,"subject_set_namespace":"default","subject_set_object":"role/admin","subject_set_relation":"member","snaptoken":"","timeouts":{"create":"1m","read":null,"update":null,"delete":null}}`,
+			wantSubjectId:  nullString,
+			wantSubjectSet: subjectSet("default", "role/admin", "member"),
+		},
+	}
+
+	ctx := context.Background()
+	server := providerserver.NewProtocol6(NewFrameworkProvider())()
+	schemaResp, err := server.GetProviderSchema(ctx, &tfprotov6.GetProviderSchemaRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stateType := schemaResp.ResourceSchemas["oryketo_relationship"].ValueType()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := server.UpgradeResourceState(ctx, &tfprotov6.UpgradeResourceStateRequest{
+				TypeName: "oryketo_relationship",
+				Version:  0,
+				RawState: &tfprotov6.RawState{JSON: []byte(tt.rawState)},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, d := range resp.Diagnostics {
+				t.Errorf("unexpected diagnostic: %s: %s", d.Summary, d.Detail)
+			}
+			if resp.UpgradedState == nil {
+				t.Fatal("no upgraded state")
+			}
+
+			state, err := resp.UpgradedState.Unmarshal(stateType)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var attributes map[string]tftypes.Value
+			if err := state.As(&attributes); err != nil {
+				t.Fatal(err)
+			}
+
+			if !attributes["subject_id"].Equal(tt.wantSubjectId) {
+				t.Errorf("subject_id = %s, want %s", attributes["subject_id"], tt.wantSubjectId)
+			}
+			if !attributes["subject_set"].Equal(tt.wantSubjectSet) {
+				t.Errorf("subject_set = %s, want %s", attributes["subject_set"], tt.wantSubjectSet)
+			}
+			if !attributes["namespace"].Equal(tftypes.NewValue(tftypes.String, "default")) {
+				t.Errorf("namespace = %s, want default", attributes["namespace"])
+			}
+		})
+	}
+}
+
+func TestRelationshipVerifyRelation(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		body        string
+		wantErr     bool
+		wantSummary string
+	}{
+		{
+			name:   "declared",
+			status: http.StatusOK,
+			body:   `{"allowed":false}`,
+		},
+		{
+			// the response of Keto v0.11 for a relation missing in the OPL
+			name:        "undeclared",
+			status:      http.StatusInternalServerError,
+			body:        `{"error":{"code":500,"status":"Internal Server Error","message":"relation \"edit\" not found"}}`,
+			wantErr:     true,
+			wantSummary: `relation "edit" of namespace "default" is not declared`,
+		},
+		{
+			name:        "other server error",
+			status:      http.StatusInternalServerError,
+			body:        `{"error":{"code":500,"status":"Internal Server Error","message":"could not connect to database"}}`,
+			wantErr:     true,
+			wantSummary: "Failed to check permission: 500 Internal Server Error",
+		},
+		{
+			name:        "gateway error",
+			status:      http.StatusInternalServerError,
+			body:        `relation "edit" not found`,
+			wantErr:     true,
+			wantSummary: "Failed to check permission: 500 Internal Server Error",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &relationshipResource{
+				provider: newTestProviderConfig(t, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+					w.Header().Set("Content-Type", "application/json")
+					w.WriteHeader(tt.status)
+					_, _ = w.Write([]byte(tt.body))
+				})),
+			}
+			subjectId := "guest"
+			rel := ketoClient.Relationship{Namespace: "default", Object: "app", Relation: "edit", SubjectId: &subjectId}
+
+			diags := r.verifyRelation(context.Background(), rel, path.Root("relation"))
+			if diags.HasError() != tt.wantErr {
+				t.Fatalf("got diagnostics %v, want error %v", diags, tt.wantErr)
+			}
+			if tt.wantErr && diags[0].Summary() != tt.wantSummary {
+				t.Errorf("got summary %q, want %q", diags[0].Summary(), tt.wantSummary)
+			}
+		})
+	}
+}
@@ -0,0 +1,362 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/id"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	ketoClient "github.com/ory/keto-client-go"
+	"github.com/ory/keto/ketoapi"
+)
+
+const (
+	relationshipPatchActionInsert = string(ketoapi.ActionInsert)
+	relationshipPatchActionDelete = string(ketoapi.ActionDelete)
+)
+
+func resourceKetoRelationships() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceKetoRelationshipsCreate,
+		ReadContext:   resourceKetoRelationshipsRead,
+		UpdateContext: resourceKetoRelationshipsUpdate,
+		DeleteContext: resourceKetoRelationshipsDelete,
+		// an update writes the changed tuples, which yields a new snaptoken
+		CustomizeDiff: customdiff.ComputedIf("snaptoken", func(ctx context.Context, d *schema.ResourceDiff, m interface{}) bool {
+			return d.HasChanges("tuples", "from_string")
+		}),
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(20 * time.Minute),
+			Read:   schema.DefaultTimeout(20 * time.Minute),
+			Update: schema.DefaultTimeout(20 * time.Minute),
+			Delete: schema.DefaultTimeout(20 * time.Minute),
+		},
+		Schema: map[string]*schema.Schema{
+			"tuples": {
+				Type:         schema.TypeSet,
+				Optional:     true,
+				ExactlyOneOf: []string{"tuples", "from_string"},
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validateRelationTupleString,
+				},
+			},
+			"from_string": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ExactlyOneOf: []string{"tuples", "from_string"},
+				ValidateFunc: validateRelationTuplesString,
+			},
+			"snaptoken": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func resourceKetoRelationshipsCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	provider := m.(*providerConfig)
+
+	tuples, err := getSchemaRelationTuples(d.Get("tuples"), d.Get("from_string"))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if diags := checkRelationTuplesNotExist(ctx, provider, tuples, relationshipsAttributePath(d)); diags.HasError() {
+		return diags
+	}
+
+	snaptoken, err := patchRelationships(ctx, provider, tuples, nil)
+	if err != nil {
+		return ketoDiagnostics(err, relationshipsAttributePath(d))
+	}
+	if err := d.Set("snaptoken", snaptoken); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(id.UniqueId())
+	return resourceKetoRelationshipsRead(ctx, d, m)
+}
+
+func resourceKetoRelationshipsRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	provider := m.(*providerConfig)
+
+	tuples, err := getSchemaRelationTuples(d.Get("tuples"), d.Get("from_string"))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	existing, err := getExistingRelationTuples(ctx, provider, tuples)
+	if err != nil {
+		return ketoDiagnostics(err, nil)
+	}
+	if len(existing) == len(tuples) {
+		return nil
+	}
+	tflog.Debug(ctx, fmt.Sprintf("%d of %d tuples missing", len(tuples)-len(existing), len(tuples)), nil)
+
+	// keep the user's notation for the tuples still present so only the
+	// missing ones show up in the diff
+	if fromString, ok := d.GetOk("from_string"); ok {
+		var lines []string
+		for _, line := range strings.Split(fromString.(string), "\n") {
+			cleanLine := strings.TrimSpace(line)
+			if cleanLine == "" {
+				continue
+			}
+			rt, err := stringToRelationTuple(cleanLine)
+			if err != nil {
+				return diag.FromErr(err)
+			}
+			if existing[rt.String()] {
+				lines = append(lines, cleanLine)
+			}
+		}
+		if err := d.Set("from_string", strings.Join(lines, "\n")); err != nil {
+			return diag.FromErr(err)
+		}
+		return nil
+	}
+
+	var present []interface{}
+	for _, raw := range d.Get("tuples").(*schema.Set).List() {
+		rt, err := stringToRelationTuple(raw.(string))
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		if existing[rt.String()] {
+			present = append(present, raw)
+		}
+	}
+	if err := d.Set("tuples", present); err != nil {
+		return diag.FromErr(err)
+	}
+	return nil
+}
+
+func resourceKetoRelationshipsUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	provider := m.(*providerConfig)
+
+	oldTuplesRaw, newTuplesRaw := d.GetChange("tuples")
+	oldFromStringRaw, newFromStringRaw := d.GetChange("from_string")
+
+	oldTuples, err := getSchemaRelationTuples(oldTuplesRaw, oldFromStringRaw)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	newTuples, err := getSchemaRelationTuples(newTuplesRaw, newFromStringRaw)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	insert, remove := diffRelationTuples(oldTuples, newTuples)
+	if diags := checkRelationTuplesNotExist(ctx, provider, insert, relationshipsAttributePath(d)); diags.HasError() {
+		return diags
+	}
+
+	snaptoken, err := patchRelationships(ctx, provider, insert, remove)
+	if err != nil {
+		return ketoDiagnostics(err, relationshipsAttributePath(d))
+	}
+	if err := d.Set("snaptoken", snaptoken); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return resourceKetoRelationshipsRead(ctx, d, m)
+}
+
+func resourceKetoRelationshipsDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	provider := m.(*providerConfig)
+
+	tuples, err := getSchemaRelationTuples(d.Get("tuples"), d.Get("from_string"))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if _, err := patchRelationships(ctx, provider, nil, tuples); err != nil {
+		return ketoDiagnostics(err, relationshipsAttributePath(d))
+	}
+
+	return nil
+}
+
+// relationshipsAttributePath returns the path of the attribute holding the
+// tuples, so errors about the tuple content point at the user's input.
+func relationshipsAttributePath(d *schema.ResourceData) cty.Path {
+	if _, ok := d.GetOk("from_string"); ok {
+		return cty.GetAttrPath("from_string")
+	}
+	return cty.GetAttrPath("tuples")
+}
+
+// getSchemaRelationTuples returns deduplicated relation tuples from either the
+// tuples set or the from_string attribute value.
+func getSchemaRelationTuples(tuplesRaw interface{}, fromStringRaw interface{}) ([]*ketoapi.RelationTuple, error) {
+	var tuples []*ketoapi.RelationTuple
+	if fromString, ok := fromStringRaw.(string); ok && fromString != "" {
+		rts, err := stringToRelationTuples(fromString)
+		if err != nil {
+			return nil, err
+		}
+		tuples = rts
+	} else if tuplesSet, ok := tuplesRaw.(*schema.Set); ok {
+		for _, raw := range tuplesSet.List() {
+			rt, err := stringToRelationTuple(raw.(string))
+			if err != nil {
+				return nil, err
+			}
+			tuples = append(tuples, rt)
+		}
+	}
+
+	keys := make(map[string]bool)
+	var list []*ketoapi.RelationTuple
+	for _, rt := range tuples {
+		key := rt.String()
+		if !keys[key] {
+			keys[key] = true
+			list = append(list, rt)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].String() < list[j].String()
+	})
+	return list, nil
+}
+
+// relationTupleGroup is the namespace, object and relation shared by the
+// tuples read with a single query.
+type relationTupleGroup struct {
+	namespace string
+	object    string
+	relation  string
+}
+
+// getExistingRelationTuples lists relationships of every namespace, object
+// and relation used by the tuples and returns a set of those tuples that
+// exist, keyed by text notation.
+func getExistingRelationTuples(ctx context.Context, provider *providerConfig, tuples []*ketoapi.RelationTuple) (map[string]bool, error) {
+	wanted := make(map[string]bool, len(tuples))
+	groups := make(map[relationTupleGroup]bool)
+	for _, rt := range tuples {
+		wanted[rt.String()] = true
+		groups[relationTupleGroup{namespace: rt.Namespace, object: rt.Object, relation: rt.Relation}] = true
+	}
+
+	existing := make(map[string]bool, len(tuples))
+	for group := range groups {
+		group := group
+		query := ketoClient.RelationQuery{
+			Namespace: &group.namespace,
+			Object:    &group.object,
+			Relation:  &group.relation,
+		}
+		relationships, err := getAllRelationships(ctx, provider, query, "")
+		if err != nil {
+			return nil, err
+		}
+		for _, relationship := range relationships {
+			key := ketoRelationshipToRelationTuple(relationship).String()
+			if wanted[key] {
+				existing[key] = true
+			}
+		}
+	}
+	return existing, nil
+}
+
+// checkRelationTuplesNotExist fails when any of the tuples to insert already
+// exists. Keto stores a duplicate row for every insert and a delete removes
+// all of them, so taking over an existing tuple would delete it on destroy.
+func checkRelationTuplesNotExist(ctx context.Context, provider *providerConfig, insert []*ketoapi.RelationTuple, attributePath cty.Path) diag.Diagnostics {
+	if len(insert) == 0 {
+		return nil
+	}
+	existing, err := getExistingRelationTuples(ctx, provider, insert)
+	if err != nil {
+		return ketoDiagnostics(err, nil)
+	}
+
+	var diags diag.Diagnostics
+	for _, rt := range insert {
+		if existing[rt.String()] {
+			diags = append(diags, diag.Diagnostic{
+				Severity:      diag.Error,
+				Summary:       fmt.Sprintf("relationship '%s' already exists", rt.String()),
+				Detail:        "Remove it from Keto or from the configuration, it can be imported as an oryketo_relationship resource.",
+				AttributePath: attributePath,
+			})
+		}
+	}
+	return diags
+}
+
+// diffRelationTuples returns tuples that need to be inserted and deleted to
+// get from the old to the new set.
+func diffRelationTuples(oldTuples, newTuples []*ketoapi.RelationTuple) (insert, remove []*ketoapi.RelationTuple) {
+	oldKeys := make(map[string]bool, len(oldTuples))
+	for _, rt := range oldTuples {
+		oldKeys[rt.String()] = true
+	}
+	newKeys := make(map[string]bool, len(newTuples))
+	for _, rt := range newTuples {
+		newKeys[rt.String()] = true
+		if !oldKeys[rt.String()] {
+			insert = append(insert, rt)
+		}
+	}
+	for _, rt := range oldTuples {
+		if !newKeys[rt.String()] {
+			remove = append(remove, rt)
+		}
+	}
+	return insert, remove
+}
+
+// patchRelationships inserts and deletes tuples in a single transaction and
+// returns the snaptoken of the write.
+func patchRelationships(ctx context.Context, provider *providerConfig, insert, remove []*ketoapi.RelationTuple) (string, error) {
+	if len(insert) == 0 && len(remove) == 0 {
+		return "", nil
+	}
+
+	patches := make([]ketoClient.RelationshipPatch, 0, len(insert)+len(remove))
+	for _, rt := range remove {
+		patches = append(patches, newRelationshipPatch(relationshipPatchActionDelete, ketoRelationTupleToRelationship(rt)))
+	}
+	for _, rt := range insert {
+		patches = append(patches, newRelationshipPatch(relationshipPatchActionInsert, ketoRelationTupleToRelationship(rt)))
+	}
+	tflog.Debug(ctx, fmt.Sprintf("patching %d inserts and %d deletes", len(insert), len(remove)), nil)
+
+	return provider.backend.patchRelationships(ctx, patches)
+}
+
+func newRelationshipPatch(action string, rel ketoClient.Relationship) ketoClient.RelationshipPatch {
+	return ketoClient.RelationshipPatch{
+		Action:        &action,
+		RelationTuple: &rel,
+	}
+}
+
+func validateRelationTupleString(v interface{}, k string) ([]string, []error) {
+	if _, err := stringToRelationTuple(v.(string)); err != nil {
+		return nil, []error{fmt.Errorf("%s: %q is not a valid relation tuple: %v", k, v, err)}
+	}
+	return nil, nil
+}
+
+func validateRelationTuplesString(v interface{}, k string) ([]string, []error) {
+	if _, err := stringToRelationTuples(v.(string)); err != nil {
+		return nil, []error{fmt.Errorf("%s: %v", k, err)}
+	}
+	return nil, nil
+}
@@ -0,0 +1,133 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/ory/keto/ketoapi"
+)
+
+// newTestProviderConfig returns a REST provider talking to a test server
+// serving handler.
+func newTestProviderConfig(t *testing.T, handler http.Handler) *providerConfig {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	config, err := newProviderConfig(providerSettings{
+		protocol:   protocolRest,
+		url:        server.URL,
+		minBackoff: "1ms",
+		maxBackoff: "1ms",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return config
+}
+
+func mustRelationTuples(t *testing.T, tuples ...string) []*ketoapi.RelationTuple {
+	t.Helper()
+	rts := make([]*ketoapi.RelationTuple, len(tuples))
+	for i, tuple := range tuples {
+		rt, err := stringToRelationTuple(tuple)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rts[i] = rt
+	}
+	return rts
+}
+
+func relationTupleStrings(rts []*ketoapi.RelationTuple) []string {
+	var tuples []string
+	for _, rt := range rts {
+		tuples = append(tuples, rt.String())
+	}
+	return tuples
+}
+
+func TestDiffRelationTuples(t *testing.T) {
+	tests := []struct {
+		name       string
+		oldTuples  []string
+		newTuples  []string
+		wantInsert []string
+		wantRemove []string
+	}{
+		{
+			name:       "create",
+			newTuples:  []string{"default:app#read@guest", "default:app#write@default:role/admin#member"},
+			wantInsert: []string{"default:app#read@guest", "default:app#write@default:role/admin#member"},
+		},
+		{
+			name:       "destroy",
+			oldTuples:  []string{"default:app#read@guest"},
+			wantRemove: []string{"default:app#read@guest"},
+		},
+		{
+			name:      "unchanged",
+			oldTuples: []string{"default:app#read@guest", "default:app#write@groups:admins"},
+			newTuples: []string{"default:app#write@groups:admins", "default:app#read@guest"},
+		},
+		{
+			name:       "replace",
+			oldTuples:  []string{"default:app#read@guest", "default:app#read@foo"},
+			newTuples:  []string{"default:app#read@guest", "default:app#read@bar"},
+			wantInsert: []string{"default:app#read@bar"},
+			wantRemove: []string{"default:app#read@foo"},
+		},
+		{
+			name:       "subject set relation",
+			oldTuples:  []string{"default:app#write@groups:admins"},
+			newTuples:  []string{"default:app#write@groups:admins#member"},
+			wantInsert: []string{"default:app#write@groups:admins#member"},
+			wantRemove: []string{"default:app#write@groups:admins"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			insert, remove := diffRelationTuples(mustRelationTuples(t, tt.oldTuples...), mustRelationTuples(t, tt.newTuples...))
+			if got := relationTupleStrings(insert); !reflect.DeepEqual(got, tt.wantInsert) {
+				t.Errorf("got insert %v, want %v", got, tt.wantInsert)
+			}
+			if got := relationTupleStrings(remove); !reflect.DeepEqual(got, tt.wantRemove) {
+				t.Errorf("got remove %v, want %v", got, tt.wantRemove)
+			}
+		})
+	}
+}
+
+func TestCheckRelationTuplesNotExist(t *testing.T) {
+	var queries []string
+	provider := newTestProviderConfig(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.Query().Get("namespace")+":"+r.URL.Query().Get("object")+"#"+r.URL.Query().Get("relation"))
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("relation") == "read" {
+			_, _ = w.Write([]byte(`{"relation_tuples":[{"namespace":"default","object":"app","relation":"read","subject_id":"guest"}],"next_page_token":""}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"relation_tuples":[],"next_page_token":""}`))
+	}))
+
+	diags := checkRelationTuplesNotExist(context.Background(), provider, mustRelationTuples(t,
+		"default:app#read@guest",
+		"default:app#read@foo",
+		"default:app#write@guest",
+	), nil)
+
+	if len(diags) != 1 || diags[0].Summary != "relationship 'default:app#read@guest' already exists" {
+		t.Fatalf("got diagnostics %v, want one for default:app#read@guest", diags)
+	}
+	if len(queries) != 2 {
+		t.Fatalf("got queries %v, want one per namespace, object and relation", queries)
+	}
+	for _, query := range queries {
+		if query != "default:app#read" && query != "default:app#write" {
+			t.Errorf("got query %q, want it limited to namespace, object and relation", query)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+var defaultRetryableStatusCodes = []int{
+	http.StatusTooManyRequests,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// retryConfig holds the settings of the retrying transport.
+type retryConfig struct {
+	maxRetries           int
+	minBackoff           time.Duration
+	maxBackoff           time.Duration
+	retryableStatusCodes map[int]bool
+}
+
+// retryTransport retries requests failing with a retryable status code or a
+// transient connection error, waiting with exponential backoff between tries.
+// Every attempt is limited by timeout on its own, zero means no timeout.
+type retryTransport struct {
+	next    http.RoundTripper
+	config  retryConfig
+	timeout time.Duration
+}
+
+func newRetryTransport(next http.RoundTripper, config retryConfig, timeout time.Duration) http.RoundTripper {
+	if config.maxRetries <= 0 && timeout <= 0 {
+		return next
+	}
+	return &retryTransport{
+		next:    next,
+		config:  config,
+		timeout: timeout,
+	}
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := t.roundTripAttempt(req, attempt)
+		if attempt >= t.config.maxRetries || !t.shouldRetry(req, resp, err) {
+			return resp, err
+		}
+		// a request body that can't be replayed can't be retried
+		if req.Body != nil && req.GetBody == nil {
+			return resp, err
+		}
+
+		wait := t.backoff(attempt, resp)
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			_ = resp.Body.Close()
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// roundTripAttempt sends a single attempt of req limited by the timeout, the
+// attempt context is released once the response body is closed.
+func (t *retryTransport) roundTripAttempt(req *http.Request, attempt int) (*http.Response, error) {
+	ctx, cancel := req.Context(), context.CancelFunc(func() {})
+	if t.timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, t.timeout)
+	}
+
+	// a RoundTripper must not modify the request, attempts are sent as
+	// clones with the body replayed on retries
+	attemptReq := req.Clone(ctx)
+	if attempt > 0 && req.Body != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		attemptReq.Body = body
+	}
+
+	resp, err := t.next.RoundTrip(attemptReq)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &cancelReadCloser{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+func (t *retryTransport) shouldRetry(req *http.Request, resp *http.Response, err error) bool {
+	if err != nil {
+		// an attempt running out of its own timeout is retried as long as
+		// the request context is alive and resending it is safe
+		if t.timeout > 0 && req.Context().Err() == nil && errors.Is(err, context.DeadlineExceeded) {
+			return isIdempotentMethod(req.Method) || isDialError(err)
+		}
+		return isRetryableError(req.Method, err)
+	}
+	return t.config.retryableStatusCodes[resp.StatusCode]
+}
+
+// cancelReadCloser cancels the context of an attempt when its response body
+// is closed.
+type cancelReadCloser struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelReadCloser) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// backoff returns the wait duration before the next attempt, Retry-After
+// header is respected when sent by the server but never exceeds max backoff.
+func (t *retryTransport) backoff(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+			return minDuration(time.Duration(seconds)*time.Second, t.config.maxBackoff)
+		}
+	}
+	wait := float64(t.config.minBackoff) * math.Pow(2, float64(attempt))
+	if wait > float64(t.config.maxBackoff) {
+		return t.config.maxBackoff
+	}
+	return time.Duration(wait)
+}
+
+// isRetryableError reports whether a request failing with err can be sent
+// again. A connection lost after the request was sent may have been applied
+// by Keto already, which is only safe to repeat for idempotent methods, tuple
+// inserts would be stored twice.
+func isRetryableError(method string, err error) bool {
+	// an expired or canceled context satisfies net.Error with Timeout() true
+	// but no retry can succeed anymore
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if isDialError(err) {
+		return true
+	}
+	if !isIdempotentMethod(method) {
+		return false
+	}
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// isDialError reports whether err happened while connecting, before anything
+// of the request was sent.
+func isDialError(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+func isIdempotentMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func minDuration(a, b time.Duration) time.Duration {
+	if a < b {
+		return a
+	}
+	return b
+}
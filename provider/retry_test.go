@@ -0,0 +1,196 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func newTestRetryClient(maxBackoff time.Duration) *http.Client {
+	return &http.Client{
+		Transport: newRetryTransport(http.DefaultTransport, retryConfig{
+			maxRetries:           3,
+			minBackoff:           time.Millisecond,
+			maxBackoff:           maxBackoff,
+			retryableStatusCodes: map[int]bool{http.StatusTooManyRequests: true, http.StatusServiceUnavailable: true},
+		}, 0),
+	}
+}
+
+func TestRetryTransportReplaysBody(t *testing.T) {
+	var mu sync.Mutex
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		bodies = append(bodies, string(body))
+		if len(bodies) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodPatch, server.URL, strings.NewReader(`[{"action":"insert"}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := newTestRetryClient(time.Millisecond).Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("got status %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	if len(bodies) != 3 {
+		t.Fatalf("got %d attempts, want 3", len(bodies))
+	}
+	for i, body := range bodies {
+		if body != `[{"action":"insert"}]` {
+			t.Errorf("attempt %d got body %q", i, body)
+		}
+	}
+}
+
+func TestRetryTransportDoesNotRetryCanceledContext(t *testing.T) {
+	var mu sync.Mutex
+	attempts := 0
+	started := make(chan struct{}, 4)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		attempts++
+		mu.Unlock()
+		started <- struct{}{}
+		<-r.Context().Done()
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-started
+		cancel()
+	}()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = newTestRetryClient(time.Millisecond).Do(req)
+	// waits for every request the server received
+	server.Close()
+
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("got error %v, want %v", err, context.Canceled)
+	}
+	if attempts != 1 {
+		t.Fatalf("got %d attempts, want 1", attempts)
+	}
+}
+
+func TestRetryTransportDoesNotResendDroppedWrites(t *testing.T) {
+	for _, method := range []string{http.MethodGet, http.MethodPatch} {
+		t.Run(method, func(t *testing.T) {
+			var mu sync.Mutex
+			attempts := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				attempts++
+				mu.Unlock()
+				// the request reached the server, the connection drops
+				// before a response is written
+				conn, _, err := w.(http.Hijacker).Hijack()
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				conn.Close()
+			}))
+			defer server.Close()
+
+			req, err := http.NewRequest(method, server.URL, strings.NewReader(`[{"action":"insert"}]`))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := newTestRetryClient(time.Millisecond).Do(req); err == nil {
+				t.Fatal("got no error")
+			}
+
+			wantAttempts := 1
+			if method == http.MethodGet {
+				wantAttempts = 4
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if attempts != wantAttempts {
+				t.Fatalf("got %d attempts, want %d", attempts, wantAttempts)
+			}
+		})
+	}
+}
+
+func TestIsRetryableError(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		err    error
+		want   bool
+	}{
+		{"canceled", http.MethodGet, context.Canceled, false},
+		{"deadline exceeded", http.MethodGet, context.DeadlineExceeded, false},
+		{"wrapped deadline exceeded", http.MethodGet, &url.Error{Op: "Get", URL: "http://keto", Err: &net.OpError{Op: "dial", Err: context.DeadlineExceeded}}, false},
+		{"unexpected eof", http.MethodGet, &url.Error{Op: "Get", URL: "http://keto", Err: io.ErrUnexpectedEOF}, true},
+		{"connection reset", http.MethodGet, &net.OpError{Op: "read", Err: syscall.ECONNRESET}, true},
+		{"delete connection reset", http.MethodDelete, &net.OpError{Op: "read", Err: syscall.ECONNRESET}, true},
+		{"patch connection reset", http.MethodPatch, &url.Error{Op: "Patch", URL: "http://keto", Err: &net.OpError{Op: "read", Err: syscall.ECONNRESET}}, false},
+		{"put unexpected eof", http.MethodPut, &url.Error{Op: "Put", URL: "http://keto", Err: io.ErrUnexpectedEOF}, false},
+		{"patch connection refused", http.MethodPatch, &url.Error{Op: "Patch", URL: "http://keto", Err: &net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isRetryableError(tt.method, tt.err); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryTransportCapsRetryAfter(t *testing.T) {
+	var mu sync.Mutex
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts++
+		if attempts == 1 {
+			w.Header().Set("Retry-After", "3600")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+	}))
+	defer server.Close()
+
+	start := time.Now()
+	resp, err := newTestRetryClient(10 * time.Millisecond).Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("got status %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	if attempts != 2 {
+		t.Fatalf("got %d attempts, want 2", attempts)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("retry waited %s, want at most max_backoff", elapsed)
+	}
+}
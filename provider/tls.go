@@ -0,0 +1,68 @@
+package provider
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// newTLSConfig builds TLS configuration for the API client, nil is returned
+// when no TLS settings are defined so the transport defaults are kept.
+func newTLSConfig(config apiClientConfig) (*tls.Config, error) {
+	if config.caCert == "" && config.clientCert == "" && config.clientKey == "" && !config.insecureSkipVerify {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: config.insecureSkipVerify,
+	}
+
+	if config.caCert != "" {
+		caCert, err := readPEM(config.caCert)
+		if err != nil {
+			return nil, fmt.Errorf("read ca_cert: %v", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(caCert) {
+			return nil, errors.New("ca_cert does not contain any valid PEM certificates")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if config.clientCert != "" || config.clientKey != "" {
+		if config.clientCert == "" || config.clientKey == "" {
+			return nil, errors.New("client_cert and client_key must be defined together")
+		}
+		clientCert, err := readPEM(config.clientCert)
+		if err != nil {
+			return nil, fmt.Errorf("read client_cert: %v", err)
+		}
+		clientKey, err := readPEM(config.clientKey)
+		if err != nil {
+			return nil, fmt.Errorf("read client_key: %v", err)
+		}
+		certificate, err := tls.X509KeyPair(clientCert, clientKey)
+		if err != nil {
+			return nil, fmt.Errorf("load client certificate: %v", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+
+	return tlsConfig, nil
+}
+
+// readPEM returns the value itself when it holds PEM content, otherwise the
+// value is treated as a path to a PEM file.
+func readPEM(value string) ([]byte, error) {
+	if strings.Contains(value, "-----BEGIN") {
+		return []byte(value), nil
+	}
+	return os.ReadFile(value)
+}
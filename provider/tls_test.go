@@ -0,0 +1,114 @@
+package provider
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newTestCertificate returns a self-signed PEM certificate and its key.
+func newTestCertificate(t *testing.T) (string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "keto"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}))
+}
+
+func TestNewTLSConfig(t *testing.T) {
+	cert, key := newTestCertificate(t)
+	_, otherKey := newTestCertificate(t)
+	block, _ := pem.Decode([]byte(cert))
+	certificate, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	certPath := filepath.Join(dir, "cert.pem")
+	keyPath := filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(certPath, []byte(cert), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyPath, []byte(key), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name             string
+		config           apiClientConfig
+		wantNil          bool
+		wantRootCAs      bool
+		wantCertificates int
+		wantErr          bool
+	}{
+		{name: "no settings", wantNil: true},
+		{name: "insecure skip verify", config: apiClientConfig{insecureSkipVerify: true}},
+		{name: "inline ca cert", config: apiClientConfig{caCert: cert}, wantRootCAs: true},
+		{name: "ca cert path", config: apiClientConfig{caCert: certPath}, wantRootCAs: true},
+		{name: "invalid ca cert", config: apiClientConfig{caCert: "-----BEGIN CERTIFICATE-----\ninvalid\n-----END CERTIFICATE-----\n"}, wantErr: true},
+		{name: "missing ca cert file", config: apiClientConfig{caCert: filepath.Join(dir, "missing.pem")}, wantErr: true},
+		{name: "inline client certificate", config: apiClientConfig{clientCert: cert, clientKey: key}, wantCertificates: 1},
+		{name: "client certificate paths", config: apiClientConfig{clientCert: certPath, clientKey: keyPath}, wantCertificates: 1},
+		{name: "mtls with ca cert", config: apiClientConfig{caCert: cert, clientCert: cert, clientKey: key}, wantRootCAs: true, wantCertificates: 1},
+		{name: "client cert without key", config: apiClientConfig{clientCert: cert}, wantErr: true},
+		{name: "client key without cert", config: apiClientConfig{clientKey: key}, wantErr: true},
+		{name: "mismatched client key", config: apiClientConfig{clientCert: cert, clientKey: otherKey}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tlsConfig, err := newTLSConfig(tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if (tlsConfig == nil) != tt.wantNil {
+				t.Fatalf("got %v, want nil %v", tlsConfig, tt.wantNil)
+			}
+			if tlsConfig == nil {
+				return
+			}
+			if tlsConfig.InsecureSkipVerify != tt.config.insecureSkipVerify {
+				t.Errorf("got insecure skip verify %v, want %v", tlsConfig.InsecureSkipVerify, tt.config.insecureSkipVerify)
+			}
+			if (tlsConfig.RootCAs != nil) != tt.wantRootCAs {
+				t.Errorf("got root CAs %v, want %v", tlsConfig.RootCAs != nil, tt.wantRootCAs)
+			}
+			if tlsConfig.RootCAs != nil {
+				if _, err := certificate.Verify(x509.VerifyOptions{Roots: tlsConfig.RootCAs}); err != nil {
+					t.Errorf("got %v verifying against the root CAs, want ca_cert trusted", err)
+				}
+			}
+			if len(tlsConfig.Certificates) != tt.wantCertificates {
+				t.Errorf("got %d certificates, want %d", len(tlsConfig.Certificates), tt.wantCertificates)
+			}
+		})
+	}
+}
@@ -0,0 +1,233 @@
+package provider
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	dschema "github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	rschema "github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	ketoClient "github.com/ory/keto-client-go"
+)
+
+// tupleModel holds the attributes of a relation tuple as declared by
+// tupleResourceAttributes and tupleDataSourceAttributes.
+type tupleModel struct {
+	Namespace  types.String `tfsdk:"namespace"`
+	Object     types.String `tfsdk:"object"`
+	Relation   types.String `tfsdk:"relation"`
+	SubjectId  types.String `tfsdk:"subject_id"`
+	SubjectSet types.Object `tfsdk:"subject_set"`
+}
+
+// subjectSetAttrTypes are the attributes of the subject_set object.
+var subjectSetAttrTypes = map[string]attr.Type{
+	"namespace": types.StringType,
+	"object":    types.StringType,
+	"relation":  types.StringType,
+}
+
+// tupleAttrTypes are the attributes of a relation tuple object, as used by
+// the provider functions and computed tuple attributes.
+var tupleAttrTypes = map[string]attr.Type{
+	"namespace":   types.StringType,
+	"object":      types.StringType,
+	"relation":    types.StringType,
+	"subject_id":  types.StringType,
+	"subject_set": types.ObjectType{AttrTypes: subjectSetAttrTypes},
+}
+
+// tupleSubjectIdValidators requires exactly one subject. The path is relative
+// to subject_id, so the validation also works for tuples nested in another
+// attribute.
+func tupleSubjectIdValidators() []validator.String {
+	return []validator.String{
+		stringvalidator.ExactlyOneOf(
+			path.MatchRelative().AtParent().AtName("subject_set"),
+		),
+	}
+}
+
+// tupleResourceAttributes returns the attributes of a relation tuple for
+// resource schemas, either at the root or as nested attributes.
+func tupleResourceAttributes() map[string]rschema.Attribute {
+	return map[string]rschema.Attribute{
+		"namespace": rschema.StringAttribute{
+			Required: true,
+		},
+		"object": rschema.StringAttribute{
+			Required: true,
+		},
+		"relation": rschema.StringAttribute{
+			Required: true,
+		},
+		"subject_id": rschema.StringAttribute{
+			Optional:   true,
+			Validators: tupleSubjectIdValidators(),
+		},
+		"subject_set": rschema.SingleNestedAttribute{
+			Optional: true,
+			Attributes: map[string]rschema.Attribute{
+				"namespace": rschema.StringAttribute{
+					Required: true,
+				},
+				"object": rschema.StringAttribute{
+					Required: true,
+				},
+				"relation": rschema.StringAttribute{
+					Optional: true,
+				},
+			},
+		},
+	}
+}
+
+// tupleDataSourceAttributes is tupleResourceAttributes for data source
+// schemas.
+func tupleDataSourceAttributes() map[string]dschema.Attribute {
+	return map[string]dschema.Attribute{
+		"namespace": dschema.StringAttribute{
+			Required: true,
+		},
+		"object": dschema.StringAttribute{
+			Required: true,
+		},
+		"relation": dschema.StringAttribute{
+			Required: true,
+		},
+		"subject_id": dschema.StringAttribute{
+			Optional:   true,
+			Validators: tupleSubjectIdValidators(),
+		},
+		"subject_set": dschema.SingleNestedAttribute{
+			Optional: true,
+			Attributes: map[string]dschema.Attribute{
+				"namespace": dschema.StringAttribute{
+					Required: true,
+				},
+				"object": dschema.StringAttribute{
+					Required: true,
+				},
+				"relation": dschema.StringAttribute{
+					Optional: true,
+				},
+			},
+		},
+	}
+}
+
+// tupleComputedDataSourceAttributes returns the attributes of a relation tuple
+// read by a data source, matching tupleAttrTypes.
+func tupleComputedDataSourceAttributes() map[string]dschema.Attribute {
+	return map[string]dschema.Attribute{
+		"namespace": dschema.StringAttribute{
+			Computed: true,
+		},
+		"object": dschema.StringAttribute{
+			Computed: true,
+		},
+		"relation": dschema.StringAttribute{
+			Computed: true,
+		},
+		"subject_id": dschema.StringAttribute{
+			Computed: true,
+		},
+		"subject_set": dschema.SingleNestedAttribute{
+			Computed: true,
+			Attributes: map[string]dschema.Attribute{
+				"namespace": dschema.StringAttribute{
+					Computed: true,
+				},
+				"object": dschema.StringAttribute{
+					Computed: true,
+				},
+				"relation": dschema.StringAttribute{
+					Computed: true,
+				},
+			},
+		},
+	}
+}
+
+// relationship returns the relationship described by the tuple.
+func (m tupleModel) relationship() ketoClient.Relationship {
+	rel := ketoClient.Relationship{
+		Namespace: m.Namespace.ValueString(),
+		Object:    m.Object.ValueString(),
+		Relation:  m.Relation.ValueString(),
+	}
+	if !m.SubjectId.IsNull() {
+		rel.SubjectId = m.SubjectId.ValueStringPointer()
+	} else {
+		rel.SubjectSet = subjectSetFromObject(m.SubjectSet)
+	}
+	return rel
+}
+
+// isKnown reports whether all values of the tuple are known, which is not
+// the case during plan when they depend on other resources.
+func (m tupleModel) isKnown() bool {
+	for _, value := range []attr.Value{m.Namespace, m.Object, m.Relation, m.SubjectId, m.SubjectSet} {
+		if value.IsUnknown() {
+			return false
+		}
+	}
+	if !m.SubjectSet.IsNull() {
+		for _, value := range m.SubjectSet.Attributes() {
+			if value.IsUnknown() {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// tupleModelFrom returns the tuple of a relationship with null values for the
+// subject attributes it doesn't use.
+func tupleModelFrom(rel ketoClient.Relationship) tupleModel {
+	return tupleModel{
+		Namespace:  types.StringValue(rel.Namespace),
+		Object:     types.StringValue(rel.Object),
+		Relation:   types.StringValue(rel.Relation),
+		SubjectId:  types.StringPointerValue(rel.SubjectId),
+		SubjectSet: subjectSetObjectValue(rel.SubjectSet),
+	}
+}
+
+// subjectSetFromObject returns the subject set held by a subject_set object,
+// nil when the object is null or unknown. A null relation is the empty
+// relation Keto uses for subject sets like groups:admins.
+func subjectSetFromObject(obj types.Object) *ketoClient.SubjectSet {
+	if obj.IsNull() || obj.IsUnknown() {
+		return nil
+	}
+	attrs := obj.Attributes()
+	return &ketoClient.SubjectSet{
+		Namespace: attrs["namespace"].(types.String).ValueString(),
+		Object:    attrs["object"].(types.String).ValueString(),
+		Relation:  attrs["relation"].(types.String).ValueString(),
+	}
+}
+
+// subjectSetObjectValue returns the subject_set object of a subject set, null
+// when it is nil. The relation of a subject set without one, which refers to
+// the object itself, is null.
+func subjectSetObjectValue(subjectSet *ketoClient.SubjectSet) types.Object {
+	if subjectSet == nil {
+		return types.ObjectNull(subjectSetAttrTypes)
+	}
+	return types.ObjectValueMust(subjectSetAttrTypes, map[string]attr.Value{
+		"namespace": types.StringValue(subjectSet.Namespace),
+		"object":    types.StringValue(subjectSet.Object),
+		"relation":  stringValueOrNull(subjectSet.Relation),
+	})
+}
+
+// stringValueOrNull returns a null value for an empty string.
+func stringValueOrNull(s string) types.String {
+	if s == "" {
+		return types.StringNull()
+	}
+	return types.StringValue(s)
+}